@@ -0,0 +1,13 @@
+// Консольный режим AOC DPS Meter без окна Wails: анализ и экспорт логов,
+// слежение за логом в терминале (например, по SSH)
+package main
+
+import (
+	"os"
+
+	"aocdpsmetr/internal/cli"
+)
+
+func main() {
+	os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
+}
@@ -1,16 +1,19 @@
 // Импортируем API Wails из сгенерированных bindings
-import { StartMonitoring, StopMonitoring, ResetStats, GetStats, GetAbilities, GetTargets, OpenDevTools } from './wailsjs/wailsjs/go/app/App.js';
+import { StartMonitoring, StopMonitoring, ResetStats, GetStats, GetAbilities, GetTargets, OpenDevTools, ChooseLogFile, ChooseAndAnalyzeFile, StartReplay, StopReplay, PauseReplay, ResumeReplay, SetReplaySpeed } from './wailsjs/wailsjs/go/app/App.js';
+import { EventsOn } from './wailsjs/wailsjs/runtime/runtime.js';
 
 class DPSMeter {
     constructor() {
         this.isMonitoring = true;
-        this.updateInterval = null;
+        this.isReplaying = false;
+        this.replayPaused = false;
         this.abilitiesSort = { column: 'damage', direction: 'desc' };
         this.targetsSort = { column: 'damage', direction: 'desc' };
         this.abilitiesCollapsed = false;
         this.targetsCollapsed = false;
         this.initializeElements();
         this.bindEvents();
+        this.subscribeBackendEvents();
         this.updateStatus('Ready to start monitoring');
     }
 
@@ -18,6 +21,11 @@ class DPSMeter {
         this.startBtn = document.getElementById('startBtn');
         this.stopBtn = document.getElementById('stopBtn');
         this.resetBtn = document.getElementById('resetBtn');
+        this.chooseLogBtn = document.getElementById('chooseLogBtn');
+        this.analyzeBtn = document.getElementById('analyzeBtn');
+        this.replaySpeed = document.getElementById('replaySpeed');
+        this.replayBtn = document.getElementById('replayBtn');
+        this.pauseReplayBtn = document.getElementById('pauseReplayBtn');
         this.debugBtn = document.getElementById('debugBtn');
         this.statusText = document.getElementById('statusText');
         this.debugPanel = document.getElementById('debugPanel');
@@ -54,6 +62,11 @@ class DPSMeter {
         this.startBtn.addEventListener('click', () => this.startMonitoring());
         this.stopBtn.addEventListener('click', () => this.stopMonitoring());
         this.resetBtn.addEventListener('click', () => this.resetStats());
+        this.chooseLogBtn.addEventListener('click', () => this.chooseLogFile());
+        this.analyzeBtn.addEventListener('click', () => this.analyzeFile());
+        this.replayBtn.addEventListener('click', () => this.toggleReplay());
+        this.pauseReplayBtn.addEventListener('click', () => this.togglePause());
+        this.replaySpeed.addEventListener('change', () => this.changeReplaySpeed());
         this.debugBtn.addEventListener('click', () => this.showDebugInfo());
         
         // Добавляем обработчики сортировки для таблиц
@@ -63,10 +76,46 @@ class DPSMeter {
         this.bindCollapseEvents();
     }
 
+    // Подписываемся на события, которые backend отправляет сам
+    subscribeBackendEvents() {
+        EventsOn('stats:update', (update) => {
+            if (this.isMonitoring) {
+                this.applyUpdate(update);
+            }
+        });
+        EventsOn('combat:started', (combat) => {
+            this.updateStatus(`Combat started: ${combat.id}`);
+        });
+        EventsOn('combat:ended', (combat) => {
+            this.updateStatus(`Combat ended: ${combat.id} (${this.formatDuration(combat.duration)})`);
+        });
+        EventsOn('log:rotated', (info) => {
+            this.updateStatus(`Log file ${info.reason}, reading from the beginning`);
+        });
+        EventsOn('monitoring:error', (info) => {
+            console.error('Monitoring error:', info.error);
+            this.updateStatus('Monitoring error: ' + info.error);
+        });
+        EventsOn('replay:finished', (state) => {
+            this.updateStatus(`Replay finished at ${state.end}`);
+        });
+        EventsOn('analysis:progress', (progress) => {
+            this.updateStatus(`Analyzing ${progress.path}: ${progress.percent.toFixed(0)}%`);
+        });
+        EventsOn('analysis:done', (result) => {
+            this.analyzeBtn.disabled = false;
+            if (result.error) {
+                this.updateStatus('Analysis failed: ' + result.error);
+                return;
+            }
+            this.updateStatus(`Analysis finished: ${result.encounters.length} encounters in ${result.path}`);
+        });
+    }
+
     async startMonitoring() {
         try {
             console.log('Starting monitoring...');
-            const result = await StartMonitoring();
+            const result = await StartMonitoring('', '');
             console.log('StartMonitoring result:', result);
             this.updateStatus(result);
             
@@ -99,6 +148,86 @@ class DPSMeter {
         }
     }
 
+    async chooseLogFile() {
+        try {
+            const result = await ChooseLogFile();
+            this.updateStatus(result);
+        } catch (error) {
+            console.error('Error choosing log file:', error);
+            this.updateStatus('Error choosing log file: ' + error.message);
+        }
+    }
+
+    async analyzeFile() {
+        try {
+            const result = await ChooseAndAnalyzeFile();
+            this.updateStatus(result);
+            if (result.startsWith('Analysis started')) {
+                this.analyzeBtn.disabled = true;
+            }
+        } catch (error) {
+            console.error('Error analyzing log file:', error);
+            this.updateStatus('Error analyzing log file: ' + error.message);
+        }
+    }
+
+    async toggleReplay() {
+        try {
+            if (this.isReplaying) {
+                const result = await StopReplay();
+                this.updateStatus(result);
+                this.setReplaying(false);
+                return;
+            }
+
+            const result = await StartReplay('', this.replaySpeed.value);
+            this.updateStatus(result);
+            if (result.startsWith('Replay started')) {
+                this.isMonitoring = true;
+                this.setReplaying(true);
+                this.startUpdating();
+            }
+        } catch (error) {
+            console.error('Error toggling replay:', error);
+            this.updateStatus('Error toggling replay: ' + error.message);
+        }
+    }
+
+    async togglePause() {
+        try {
+            const result = this.replayPaused ? await ResumeReplay() : await PauseReplay();
+            this.updateStatus(result);
+            this.replayPaused = !this.replayPaused;
+            this.pauseReplayBtn.textContent = this.replayPaused ? 'Resume' : 'Pause';
+        } catch (error) {
+            console.error('Error pausing replay:', error);
+            this.updateStatus('Error pausing replay: ' + error.message);
+        }
+    }
+
+    async changeReplaySpeed() {
+        if (!this.isReplaying) {
+            return;
+        }
+        try {
+            const result = await SetReplaySpeed(this.replaySpeed.value);
+            this.updateStatus(result);
+        } catch (error) {
+            console.error('Error changing replay speed:', error);
+            this.updateStatus('Error changing replay speed: ' + error.message);
+        }
+    }
+
+    // Кнопки мониторинга недоступны, пока идет воспроизведение
+    setReplaying(active) {
+        this.isReplaying = active;
+        this.replayPaused = false;
+        this.replayBtn.textContent = active ? 'Stop Replay' : 'Replay Log';
+        this.pauseReplayBtn.textContent = 'Pause';
+        this.pauseReplayBtn.disabled = !active;
+        this.startBtn.disabled = active;
+    }
+
     async resetStats() {
         try {
             const result = await ResetStats();
@@ -116,7 +245,6 @@ class DPSMeter {
             
             const debugInfo = {
                 isMonitoring: this.isMonitoring,
-                updateInterval: this.updateInterval,
                 timestamp: new Date().toISOString()
             };
             
@@ -168,20 +296,12 @@ class DPSMeter {
     }
 
     startUpdating() {
-        // Обновляем статистику каждую секунду
-        this.updateInterval = setInterval(() => {
-            this.updateStats();
-        }, 1000);
-        
-        // Первое обновление сразу
+        // Дальше обновления приходят событием stats:update
         this.updateStats();
     }
 
     stopUpdating() {
-        if (this.updateInterval) {
-            clearInterval(this.updateInterval);
-            this.updateInterval = null;
-        }
+        // События stats:update игнорируются, пока мониторинг остановлен
     }
 
     async updateStats() {
@@ -191,20 +311,23 @@ class DPSMeter {
                 GetAbilities(),
                 GetTargets()
             ]);
-
-            // Сохраняем данные для сортировки
-            this.lastAbilities = abilities;
-            this.lastTargets = targets;
-
-            // console.log('Stats:', stats); // Раскомментируйте для отладки
-            this.updateStatsDisplay(stats);
-            this.updateAbilitiesTable(abilities);
-            this.updateTargetsTable(targets);
+            this.applyUpdate({ stats, abilities, targets });
         } catch (error) {
             console.error('Error updating stats:', error);
         }
     }
 
+    applyUpdate({ stats, abilities, targets }) {
+        // Сохраняем данные для сортировки
+        this.lastAbilities = abilities;
+        this.lastTargets = targets;
+
+        // console.log('Stats:', stats); // Раскомментируйте для отладки
+        this.updateStatsDisplay(stats);
+        this.updateAbilitiesTable(abilities);
+        this.updateTargetsTable(targets);
+    }
+
     updateStatsDisplay(stats) {
         // Обновляем значения
         this.maxDpsElement.textContent = this.formatNumber(stats.maxDps);
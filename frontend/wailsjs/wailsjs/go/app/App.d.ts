@@ -1,18 +1,91 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
+import {app} from '../models';
+import {settings} from '../models';
+import {source} from '../models';
 
-export function GetAbilities():Promise<Array<Record<string, any>>>;
+export function AnalyzeFile(arg1:string):Promise<string>;
+
+export function CancelAnalysis():Promise<string>;
+
+export function ChooseAndAnalyzeFile():Promise<string>;
+
+export function ChooseLogFile():Promise<string>;
+
+export function FollowLogDirectory(arg1:boolean):Promise<string>;
+
+export function GetAPIVersion():Promise<number>;
+
+export function GetAbilities():Promise<Array<app.AbilityRow>>;
+
+export function GetAbilityDetail(arg1:string,arg2:string):Promise<app.AbilityDetail>;
+
+export function GetAnalysis():Promise<app.AnalysisResult>;
+
+export function GetBuffAttribution(arg1:string):Promise<Array<app.BuffAttribution>>;
+
+export function GetBuffs(arg1:string):Promise<Array<app.BuffRow>>;
+
+export function GetDamageTaken():Promise<app.DamageTaken>;
+
+export function GetDamageTakenByAbility():Promise<Array<app.DamageTakenRow>>;
+
+export function GetDamageTakenBySource():Promise<Array<app.DamageTakenRow>>;
+
+export function GetEncounter(arg1:string):Promise<app.Encounter>;
+
+export function GetEncounters():Promise<Array<app.EncounterSummary>>;
+
+export function GetLogCandidates():Promise<Array<app.LogCandidate>>;
 
 export function GetLogPath():Promise<string>;
 
-export function GetStats():Promise<Record<string, any>>;
+export function GetReplayState():Promise<app.ReplayState>;
+
+export function GetSegmentation():Promise<app.Segmentation>;
+
+export function GetSettings():Promise<settings.Settings>;
 
-export function GetTargets():Promise<Array<Record<string, any>>>;
+export function GetSourceKinds():Promise<Array<string>>;
+
+export function GetStats():Promise<app.Stats>;
+
+export function GetTargets():Promise<Array<app.TargetRow>>;
+
+export function GetTimeline(arg1:string):Promise<Array<app.TimelinePoint>>;
+
+export function GetUpdateRate():Promise<number>;
 
 export function OpenDevTools():Promise<string>;
 
+export function PauseReplay():Promise<string>;
+
 export function ResetStats():Promise<string>;
 
-export function StartMonitoring():Promise<string>;
+export function ResumeReplay():Promise<string>;
+
+export function SaveSettings(arg1:settings.Settings):Promise<string>;
+
+export function SeekReplay(arg1:string):Promise<string>;
+
+export function SetLogPath(arg1:string):Promise<string>;
+
+export function SetReplaySpeed(arg1:string):Promise<string>;
+
+export function SetSegmentation(arg1:string,arg2:number):Promise<string>;
+
+export function SetUpdateRate(arg1:number):Promise<string>;
+
+export function StartCombat():Promise<string>;
+
+export function StartMonitoring(arg1:string,arg2:string):Promise<string>;
+
+export function StartReplay(arg1:string,arg2:string):Promise<string>;
+
+export function StartSources(arg1:Array<source.Config>):Promise<string>;
+
+export function StopCombat():Promise<string>;
 
 export function StopMonitoring():Promise<string>;
+
+export function StopReplay():Promise<string>;
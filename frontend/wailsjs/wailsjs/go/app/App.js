@@ -2,14 +2,94 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AnalyzeFile(arg1) {
+  return window['go']['app']['App']['AnalyzeFile'](arg1);
+}
+
+export function CancelAnalysis() {
+  return window['go']['app']['App']['CancelAnalysis']();
+}
+
+export function ChooseAndAnalyzeFile() {
+  return window['go']['app']['App']['ChooseAndAnalyzeFile']();
+}
+
+export function ChooseLogFile() {
+  return window['go']['app']['App']['ChooseLogFile']();
+}
+
+export function FollowLogDirectory(arg1) {
+  return window['go']['app']['App']['FollowLogDirectory'](arg1);
+}
+
+export function GetAPIVersion() {
+  return window['go']['app']['App']['GetAPIVersion']();
+}
+
 export function GetAbilities() {
   return window['go']['app']['App']['GetAbilities']();
 }
 
+export function GetAbilityDetail(arg1, arg2) {
+  return window['go']['app']['App']['GetAbilityDetail'](arg1, arg2);
+}
+
+export function GetAnalysis() {
+  return window['go']['app']['App']['GetAnalysis']();
+}
+
+export function GetBuffAttribution(arg1) {
+  return window['go']['app']['App']['GetBuffAttribution'](arg1);
+}
+
+export function GetBuffs(arg1) {
+  return window['go']['app']['App']['GetBuffs'](arg1);
+}
+
+export function GetDamageTaken() {
+  return window['go']['app']['App']['GetDamageTaken']();
+}
+
+export function GetDamageTakenByAbility() {
+  return window['go']['app']['App']['GetDamageTakenByAbility']();
+}
+
+export function GetDamageTakenBySource() {
+  return window['go']['app']['App']['GetDamageTakenBySource']();
+}
+
+export function GetEncounter(arg1) {
+  return window['go']['app']['App']['GetEncounter'](arg1);
+}
+
+export function GetEncounters() {
+  return window['go']['app']['App']['GetEncounters']();
+}
+
+export function GetLogCandidates() {
+  return window['go']['app']['App']['GetLogCandidates']();
+}
+
 export function GetLogPath() {
   return window['go']['app']['App']['GetLogPath']();
 }
 
+export function GetReplayState() {
+  return window['go']['app']['App']['GetReplayState']();
+}
+
+export function GetSegmentation() {
+  return window['go']['app']['App']['GetSegmentation']();
+}
+
+export function GetSettings() {
+  return window['go']['app']['App']['GetSettings']();
+}
+
+export function GetSourceKinds() {
+  return window['go']['app']['App']['GetSourceKinds']();
+}
+
 export function GetStats() {
   return window['go']['app']['App']['GetStats']();
 }
@@ -18,18 +98,78 @@ export function GetTargets() {
   return window['go']['app']['App']['GetTargets']();
 }
 
+export function GetTimeline(arg1) {
+  return window['go']['app']['App']['GetTimeline'](arg1);
+}
+
+export function GetUpdateRate() {
+  return window['go']['app']['App']['GetUpdateRate']();
+}
+
 export function OpenDevTools() {
   return window['go']['app']['App']['OpenDevTools']();
 }
 
+export function PauseReplay() {
+  return window['go']['app']['App']['PauseReplay']();
+}
+
 export function ResetStats() {
   return window['go']['app']['App']['ResetStats']();
 }
 
-export function StartMonitoring() {
-  return window['go']['app']['App']['StartMonitoring']();
+export function ResumeReplay() {
+  return window['go']['app']['App']['ResumeReplay']();
+}
+
+export function SaveSettings(arg1) {
+  return window['go']['app']['App']['SaveSettings'](arg1);
+}
+
+export function SeekReplay(arg1) {
+  return window['go']['app']['App']['SeekReplay'](arg1);
+}
+
+export function SetLogPath(arg1) {
+  return window['go']['app']['App']['SetLogPath'](arg1);
+}
+
+export function SetReplaySpeed(arg1) {
+  return window['go']['app']['App']['SetReplaySpeed'](arg1);
+}
+
+export function SetSegmentation(arg1, arg2) {
+  return window['go']['app']['App']['SetSegmentation'](arg1, arg2);
+}
+
+export function SetUpdateRate(arg1) {
+  return window['go']['app']['App']['SetUpdateRate'](arg1);
+}
+
+export function StartCombat() {
+  return window['go']['app']['App']['StartCombat']();
+}
+
+export function StartMonitoring(arg1, arg2) {
+  return window['go']['app']['App']['StartMonitoring'](arg1, arg2);
+}
+
+export function StartReplay(arg1, arg2) {
+  return window['go']['app']['App']['StartReplay'](arg1, arg2);
+}
+
+export function StartSources(arg1) {
+  return window['go']['app']['App']['StartSources'](arg1);
+}
+
+export function StopCombat() {
+  return window['go']['app']['App']['StopCombat']();
 }
 
 export function StopMonitoring() {
   return window['go']['app']['App']['StopMonitoring']();
 }
+
+export function StopReplay() {
+  return window['go']['app']['App']['StopReplay']();
+}
@@ -0,0 +1,672 @@
+export namespace app {
+	
+	export class HistogramBin {
+	    min: number;
+	    max: number;
+	    count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new HistogramBin(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.min = source["min"];
+	        this.max = source["max"];
+	        this.count = source["count"];
+	    }
+	}
+	export class AbilityDetail {
+	    name: string;
+	    damage: number;
+	    hits: number;
+	    crits: number;
+	    minHit: number;
+	    maxHit: number;
+	    avgHit: number;
+	    avgNonCrit: number;
+	    avgCrit: number;
+	    critMultiplier: number;
+	    histogram: HistogramBin[];
+	
+	    static createFrom(source: any = {}) {
+	        return new AbilityDetail(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.damage = source["damage"];
+	        this.hits = source["hits"];
+	        this.crits = source["crits"];
+	        this.minHit = source["minHit"];
+	        this.maxHit = source["maxHit"];
+	        this.avgHit = source["avgHit"];
+	        this.avgNonCrit = source["avgNonCrit"];
+	        this.avgCrit = source["avgCrit"];
+	        this.critMultiplier = source["critMultiplier"];
+	        this.histogram = this.convertValues(source["histogram"], HistogramBin);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class AbilityRow {
+	    name: string;
+	    damage: number;
+	    healing: number;
+	    hits: number;
+	    crits: number;
+	    critRate: number;
+	    healingHits: number;
+	    healingCrits: number;
+	    healingCritRate: number;
+	    minHit: number;
+	    maxHit: number;
+	    avgHit: number;
+	    avgNonCrit: number;
+	    avgCrit: number;
+	    critMultiplier: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new AbilityRow(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.damage = source["damage"];
+	        this.healing = source["healing"];
+	        this.hits = source["hits"];
+	        this.crits = source["crits"];
+	        this.critRate = source["critRate"];
+	        this.healingHits = source["healingHits"];
+	        this.healingCrits = source["healingCrits"];
+	        this.healingCritRate = source["healingCritRate"];
+	        this.minHit = source["minHit"];
+	        this.maxHit = source["maxHit"];
+	        this.avgHit = source["avgHit"];
+	        this.avgNonCrit = source["avgNonCrit"];
+	        this.avgCrit = source["avgCrit"];
+	        this.critMultiplier = source["critMultiplier"];
+	    }
+	}
+	export class EncounterSummary {
+	    id: string;
+	    startTime: string;
+	    endTime: string;
+	    duration: number;
+	    isActive: boolean;
+	    damage: number;
+	    hits: number;
+	    crits: number;
+	    critRate: number;
+	    healing: number;
+	    kills: number;
+	    damageTaken: number;
+	    dps: number;
+	    hps: number;
+	    dtps: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new EncounterSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.startTime = source["startTime"];
+	        this.endTime = source["endTime"];
+	        this.duration = source["duration"];
+	        this.isActive = source["isActive"];
+	        this.damage = source["damage"];
+	        this.hits = source["hits"];
+	        this.crits = source["crits"];
+	        this.critRate = source["critRate"];
+	        this.healing = source["healing"];
+	        this.kills = source["kills"];
+	        this.damageTaken = source["damageTaken"];
+	        this.dps = source["dps"];
+	        this.hps = source["hps"];
+	        this.dtps = source["dtps"];
+	    }
+	}
+	export class AnalysisResult {
+	    path: string;
+	    encounters: EncounterSummary[];
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AnalysisResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.encounters = this.convertValues(source["encounters"], EncounterSummary);
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BuffAbilityDamage {
+	    name: string;
+	    damage: number;
+	    totalDamage: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BuffAbilityDamage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.damage = source["damage"];
+	        this.totalDamage = source["totalDamage"];
+	    }
+	}
+	export class BuffAttribution {
+	    name: string;
+	    target: string;
+	    isDebuff: boolean;
+	    activeTime: number;
+	    inactiveTime: number;
+	    damageActive: number;
+	    damageInactive: number;
+	    dpsActive: number;
+	    dpsInactive: number;
+	    gain: number;
+	    abilities: BuffAbilityDamage[];
+	
+	    static createFrom(source: any = {}) {
+	        return new BuffAttribution(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.target = source["target"];
+	        this.isDebuff = source["isDebuff"];
+	        this.activeTime = source["activeTime"];
+	        this.inactiveTime = source["inactiveTime"];
+	        this.damageActive = source["damageActive"];
+	        this.damageInactive = source["damageInactive"];
+	        this.dpsActive = source["dpsActive"];
+	        this.dpsInactive = source["dpsInactive"];
+	        this.gain = source["gain"];
+	        this.abilities = this.convertValues(source["abilities"], BuffAbilityDamage);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BuffRow {
+	    name: string;
+	    target: string;
+	    isDebuff: boolean;
+	    uptime: number;
+	    applications: number;
+	    refreshes: number;
+	    avgDuration: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BuffRow(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.target = source["target"];
+	        this.isDebuff = source["isDebuff"];
+	        this.uptime = source["uptime"];
+	        this.applications = source["applications"];
+	        this.refreshes = source["refreshes"];
+	        this.avgDuration = source["avgDuration"];
+	    }
+	}
+	export class DamageTaken {
+	    damage: number;
+	    hits: number;
+	    crits: number;
+	    critRate: number;
+	    dtps: number;
+	    maxDtps: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DamageTaken(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.damage = source["damage"];
+	        this.hits = source["hits"];
+	        this.crits = source["crits"];
+	        this.critRate = source["critRate"];
+	        this.dtps = source["dtps"];
+	        this.maxDtps = source["maxDtps"];
+	    }
+	}
+	export class DamageTakenRow {
+	    name: string;
+	    damage: number;
+	    hits: number;
+	    crits: number;
+	    critRate: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DamageTakenRow(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.damage = source["damage"];
+	        this.hits = source["hits"];
+	        this.crits = source["crits"];
+	        this.critRate = source["critRate"];
+	    }
+	}
+	export class TargetRow {
+	    name: string;
+	    damage: number;
+	    healing: number;
+	    hits: number;
+	    crits: number;
+	    critRate: number;
+	    kills: number;
+	    healingHits: number;
+	    healingCrits: number;
+	    healingCritRate: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TargetRow(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.damage = source["damage"];
+	        this.healing = source["healing"];
+	        this.hits = source["hits"];
+	        this.crits = source["crits"];
+	        this.critRate = source["critRate"];
+	        this.kills = source["kills"];
+	        this.healingHits = source["healingHits"];
+	        this.healingCrits = source["healingCrits"];
+	        this.healingCritRate = source["healingCritRate"];
+	    }
+	}
+	export class Encounter {
+	    summary: EncounterSummary;
+	    abilities: AbilityRow[];
+	    targets: TargetRow[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Encounter(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.summary = this.convertValues(source["summary"], EncounterSummary);
+	        this.abilities = this.convertValues(source["abilities"], AbilityRow);
+	        this.targets = this.convertValues(source["targets"], TargetRow);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	export class LogCandidate {
+	    path: string;
+	    source: string;
+	    modTime: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LogCandidate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.source = source["source"];
+	        this.modTime = source["modTime"];
+	    }
+	}
+	export class ReplayState {
+	    active: boolean;
+	    speed: string;
+	    paused: boolean;
+	    finished: boolean;
+	    position: string;
+	    start: string;
+	    end: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReplayState(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.active = source["active"];
+	        this.speed = source["speed"];
+	        this.paused = source["paused"];
+	        this.finished = source["finished"];
+	        this.position = source["position"];
+	        this.start = source["start"];
+	        this.end = source["end"];
+	    }
+	}
+	export class Segmentation {
+	    mode: string;
+	    idleTimeout: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Segmentation(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.mode = source["mode"];
+	        this.idleTimeout = source["idleTimeout"];
+	    }
+	}
+	export class Stats {
+	    version: number;
+	    maxDps: number;
+	    dps: number;
+	    avgDps: number;
+	    dps15s: number;
+	    dps30s: number;
+	    damage: number;
+	    hits: number;
+	    crits: number;
+	    maxHps: number;
+	    hps: number;
+	    avgHps: number;
+	    hps15s: number;
+	    hps30s: number;
+	    healing: number;
+	    healingHits: number;
+	    healingCrits: number;
+	    critRate: number;
+	    healingCritRate: number;
+	    kills: number;
+	    duration: number;
+	    isActive: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Stats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.version = source["version"];
+	        this.maxDps = source["maxDps"];
+	        this.dps = source["dps"];
+	        this.avgDps = source["avgDps"];
+	        this.dps15s = source["dps15s"];
+	        this.dps30s = source["dps30s"];
+	        this.damage = source["damage"];
+	        this.hits = source["hits"];
+	        this.crits = source["crits"];
+	        this.maxHps = source["maxHps"];
+	        this.hps = source["hps"];
+	        this.avgHps = source["avgHps"];
+	        this.hps15s = source["hps15s"];
+	        this.hps30s = source["hps30s"];
+	        this.healing = source["healing"];
+	        this.healingHits = source["healingHits"];
+	        this.healingCrits = source["healingCrits"];
+	        this.critRate = source["critRate"];
+	        this.healingCritRate = source["healingCritRate"];
+	        this.kills = source["kills"];
+	        this.duration = source["duration"];
+	        this.isActive = source["isActive"];
+	    }
+	}
+	
+	export class TimelinePoint {
+	    second: number;
+	    damage: number;
+	    healing: number;
+	    damageTaken: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TimelinePoint(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.second = source["second"];
+	        this.damage = source["damage"];
+	        this.healing = source["healing"];
+	        this.damageTaken = source["damageTaken"];
+	    }
+	}
+
+}
+
+export namespace settings {
+	
+	export class Logs {
+	    chosenPath: string;
+	    searchPaths: string[];
+	    pollIntervalMs: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Logs(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.chosenPath = source["chosenPath"];
+	        this.searchPaths = source["searchPaths"];
+	        this.pollIntervalMs = source["pollIntervalMs"];
+	    }
+	}
+	export class Monitoring {
+	    startMode: string;
+	    startTime: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Monitoring(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.startMode = source["startMode"];
+	        this.startTime = source["startTime"];
+	    }
+	}
+	export class Segmentation {
+	    mode: string;
+	    idleTimeoutSeconds: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Segmentation(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.mode = source["mode"];
+	        this.idleTimeoutSeconds = source["idleTimeoutSeconds"];
+	    }
+	}
+	export class Updates {
+	    maxPerSecond: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Updates(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.maxPerSecond = source["maxPerSecond"];
+	    }
+	}
+	export class Settings {
+	    version: number;
+	    logs: Logs;
+	    segmentation: Segmentation;
+	    monitoring: Monitoring;
+	    updates: Updates;
+	
+	    static createFrom(source: any = {}) {
+	        return new Settings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.version = source["version"];
+	        this.logs = this.convertValues(source["logs"], Logs);
+	        this.segmentation = this.convertValues(source["segmentation"], Segmentation);
+	        this.monitoring = this.convertValues(source["monitoring"], Monitoring);
+	        this.updates = this.convertValues(source["updates"], Updates);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace source {
+	
+	export class Config {
+	    kind: string;
+	    path: string;
+	    address: string;
+	    speed: string;
+	    importBacklog: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Config(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kind = source["kind"];
+	        this.path = source["path"];
+	        this.address = source["address"];
+	        this.speed = source["speed"];
+	        this.importBacklog = source["importBacklog"];
+	    }
+	}
+
+}
+
+export namespace watcher {
+	
+	export class StartPosition {
+	    Mode: string;
+	    // Go type: time
+	    Since: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new StartPosition(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Mode = source["Mode"];
+	        this.Since = this.convertValues(source["Since"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
@@ -0,0 +1,66 @@
+package analysis
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"aocdpsmetr/internal/metrics"
+	"aocdpsmetr/internal/parser"
+)
+
+// Progress сообщает о ходе анализа: прочитано read байт из total
+type Progress func(read, total int64)
+
+// Options параметры анализа
+type Options struct {
+	Policy   metrics.SegmentationPolicy // nil - политика калькулятора по умолчанию
+	Progress Progress                   // Вызывается после каждой пачки событий и в конце
+	Log      io.Writer                  // Отладочный вывод калькулятора; nil - os.Stdout
+}
+
+// AnalyzeFile читает лог целиком и рассчитывает статистику по времени событий
+func AnalyzeFile(ctx context.Context, path string, opts Options) (*metrics.CombatSession, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	return Analyze(ctx, file, info.Size(), opts)
+}
+
+// Analyze читает лог из r и рассчитывает статистику. Все расчеты ведутся по
+// времени событий, поэтому результат не зависит от скорости чтения. В конце
+// сессия завершается, и последний бой попадает в список боев.
+func Analyze(ctx context.Context, r io.Reader, total int64, opts Options) (*metrics.CombatSession, error) {
+	calculator := metrics.NewCalculator()
+	if opts.Policy != nil {
+		calculator.SetSegmentationPolicy(opts.Policy)
+	}
+	if opts.Log != nil {
+		calculator.SetLogOutput(opts.Log)
+	}
+
+	read, err := parser.LineReader{}.Read(r, func(batch []parser.Event, read int64) error {
+		calculator.ProcessEvents(batch)
+		if opts.Progress != nil {
+			opts.Progress(read, total)
+		}
+		return ctx.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	if opts.Progress != nil {
+		opts.Progress(read, total)
+	}
+
+	calculator.EndSession()
+	return calculator.GetSession(), nil
+}
@@ -0,0 +1,179 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"aocdpsmetr/internal/analysis"
+	"aocdpsmetr/internal/metrics"
+	"aocdpsmetr/internal/parser"
+)
+
+// analysisRun запущенный анализ файла
+type analysisRun struct {
+	path   string
+	cancel context.CancelFunc
+}
+
+// AnalyzeFile запускает разбор всего файла лога без мониторинга. Статистика
+// считается по времени событий в отдельном калькуляторе и не смешивается с
+// текущей сессией. Ход анализа приходит событиями EventAnalysisProgress,
+// результат - событием EventAnalysisDone. Новый вызов отменяет предыдущий анализ.
+func (a *App) AnalyzeFile(path string) string {
+	path = filepath.Clean(path)
+
+	ok, err := parser.IsCombatLog(path)
+	if err != nil {
+		return "Cannot read log file: " + err.Error()
+	}
+	if !ok {
+		return "Not an AOC combat log (no " + parser.CombatCategory + " lines): " + path
+	}
+
+	parent := a.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	run := &analysisRun{path: path, cancel: cancel}
+
+	a.analysisMu.Lock()
+	if a.analysisRun != nil {
+		a.analysisRun.cancel()
+	}
+	a.analysisRun = run
+	a.analysisMu.Unlock()
+
+	go a.runAnalysis(ctx, run)
+	return "Analysis started: " + path
+}
+
+// ChooseAndAnalyzeFile открывает системный диалог выбора файла и запускает его анализ
+func (a *App) ChooseAndAnalyzeFile() string {
+	path, err := a.openLogDialog("Choose log file to analyze")
+	if err != nil {
+		fmt.Println("Failed to open file dialog:", err)
+		return "Failed to open file dialog: " + err.Error()
+	}
+	if path == "" {
+		return "No file chosen"
+	}
+
+	return a.AnalyzeFile(path)
+}
+
+// CancelAnalysis отменяет текущий анализ файла
+func (a *App) CancelAnalysis() string {
+	a.analysisMu.Lock()
+	defer a.analysisMu.Unlock()
+
+	if a.analysisRun == nil {
+		return "No analysis in progress"
+	}
+	a.analysisRun.cancel()
+	a.analysisRun = nil
+	return "Analysis canceled"
+}
+
+// GetAnalysis возвращает результат последнего завершенного анализа
+func (a *App) GetAnalysis() AnalysisResult {
+	a.analysisMu.Lock()
+	defer a.analysisMu.Unlock()
+
+	if a.analysis == nil {
+		return AnalysisResult{Encounters: []EncounterSummary{}}
+	}
+	return analysisResult(a.analysisPath, a.analysis)
+}
+
+// runAnalysis разбирает файл и сохраняет результат, если анализ не был отменен
+func (a *App) runAnalysis(ctx context.Context, run *analysisRun) {
+	defer run.cancel()
+	fmt.Println("Analyzing log file:", run.path)
+
+	// В ручном режиме бои не начинаются сами, поэтому файл делим по паузам
+	var policy metrics.SegmentationPolicy
+	if current := a.calculator.GetSegmentationPolicy(); current.Mode() != metrics.SegmentManual {
+		policy = current
+	}
+
+	lastPercent := -1
+	session, err := analysis.AnalyzeFile(ctx, run.path, analysis.Options{
+		Policy: policy,
+		Progress: func(read, total int64) {
+			// Большие файлы дают тысячи пачек - отправляем только изменение процента
+			progress := analysisProgress(run.path, read, total)
+			if percent := int(progress.Percent); percent != lastPercent {
+				lastPercent = percent
+				a.emit(EventAnalysisProgress, progress)
+			}
+		},
+	})
+
+	a.analysisMu.Lock()
+	if a.analysisRun == run {
+		a.analysisRun = nil
+	}
+	if err == nil {
+		a.analysis = session
+		a.analysisPath = run.path
+	}
+	a.analysisMu.Unlock()
+
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			err = errors.New("analysis canceled")
+		}
+		fmt.Println("Analysis failed:", err)
+		a.emit(EventAnalysisDone, AnalysisResult{Path: run.path, Encounters: []EncounterSummary{}, Error: err.Error()})
+		return
+	}
+
+	result := analysisResult(run.path, session)
+	fmt.Printf("Analysis finished: %d encounters\n", len(result.Encounters))
+	a.emit(EventAnalysisDone, result)
+}
+
+// analyzedEncounter ищет бой в результате последнего анализа
+func (a *App) analyzedEncounter(id string) *metrics.Combat {
+	a.analysisMu.Lock()
+	defer a.analysisMu.Unlock()
+
+	if a.analysis == nil {
+		return nil
+	}
+	for _, combat := range a.analysis.Encounters {
+		if combat.ID == id {
+			return combat
+		}
+	}
+	return nil
+}
+
+// findEncounter ищет бой сначала в текущей сессии, затем в результате анализа
+func (a *App) findEncounter(id string) *metrics.Combat {
+	if combat := a.calculator.GetEncounter(id); combat != nil {
+		return combat
+	}
+	return a.analyzedEncounter(id)
+}
+
+// analysisResult собирает список боев проанализированного файла
+func analysisResult(path string, session *metrics.CombatSession) AnalysisResult {
+	encounters := make([]EncounterSummary, 0, len(session.Encounters))
+	for _, combat := range session.Encounters {
+		encounters = append(encounters, encounterSummary(combat))
+	}
+	return AnalysisResult{Path: path, Encounters: encounters}
+}
+
+// analysisProgress собирает данные события EventAnalysisProgress
+func analysisProgress(path string, read, total int64) AnalysisProgress {
+	percent := 100.0
+	if total > 0 && read < total {
+		percent = float64(read) / float64(total) * 100
+	}
+	return AnalysisProgress{Path: path, Read: read, Total: total, Percent: percent}
+}
@@ -5,30 +5,104 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"aocdpsmetr/internal/locator"
 	"aocdpsmetr/internal/metrics"
+	"aocdpsmetr/internal/parser"
+	"aocdpsmetr/internal/settings"
+	"aocdpsmetr/internal/source"
 	"aocdpsmetr/internal/watcher"
 )
 
 // App struct
 type App struct {
-	ctx        context.Context
-	calculator *metrics.Calculator
-	watcher    *watcher.Watcher
+	ctx          context.Context
+	calculator   *metrics.Calculator
+	lifecycle    sync.Mutex         // Упорядочивает запуск и остановку источника; берется до mu
+	mu           sync.Mutex         // Защищает источник событий и настройки
+	source       source.EventSource // Текущий источник событий, nil - мониторинг остановлен
+	stopSource   context.CancelFunc // Останавливает чтение каналов источника
+	settings     *settings.Settings
+	settingsPath string
+	dirty        atomic.Bool  // Статистика изменилась с последнего обновления фронтенда
+	updateEvery  atomic.Int64 // Минимальный интервал между обновлениями, в наносекундах
+	stopUpdates  context.CancelFunc
+	analysisMu   sync.Mutex             // Защищает поля анализа файла
+	analysisRun  *analysisRun           // Анализ, который выполняется сейчас
+	analysis     *metrics.CombatSession // Результат последнего анализа
+	analysisPath string                 // Файл последнего анализа
 }
 
 // NewApp creates a new App application struct
 func NewApp() *App {
-	return &App{
+	a := &App{
 		calculator: metrics.NewCalculator(),
+		settings:   settings.Default(),
 	}
+	a.loadSettings()
+
+	a.calculator.OnChange(func() { a.dirty.Store(true) })
+	a.calculator.OnCombatStarted(a.onCombatStarted)
+	a.calculator.OnCombatEnded(a.onCombatEnded)
+	return a
+}
+
+// loadSettings читает настройки пользователя и применяет их
+func (a *App) loadSettings() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	path, err := settings.DefaultPath()
+	if err != nil {
+		fmt.Println("Settings disabled:", err)
+		return
+	}
+	a.settingsPath = path
+
+	// При ошибке Load возвращает настройки по умолчанию
+	loaded, err := settings.Load(path)
+	if err != nil {
+		fmt.Printf("Failed to load settings from %s, using defaults: %v\n", path, err)
+
+		// Файл может быть от более новой версии или исправлен вручную, поэтому
+		// не затираем его настройками по умолчанию
+		backup, backupErr := settings.Backup(path)
+		if backupErr != nil {
+			fmt.Println("Settings will not be saved to keep the unreadable file:", backupErr)
+			a.settingsPath = ""
+		} else {
+			fmt.Println("Unreadable settings file moved to", backup)
+		}
+	}
+	if err := a.updateSettings(loaded); err != nil {
+		fmt.Println("Failed to apply settings, using defaults:", err)
+		a.updateSettings(settings.Default())
+	}
+}
+
+// saveSettings сохраняет текущие настройки. Вызывается под a.mu.
+func (a *App) saveSettings() error {
+	if a.settingsPath == "" {
+		return fmt.Errorf("settings path is not available")
+	}
+	return settings.Save(a.settingsPath, a.settings)
 }
 
 // startup is called when the app starts. The context is saved
 // so we can call the runtime methods
 func (a *App) Startup(ctx context.Context) {
 	a.ctx = ctx
+
+	updates, cancel := context.WithCancel(ctx)
+	a.stopUpdates = cancel
+	go a.pushUpdates(updates)
+
 	fmt.Println("App startup completed")
 }
 
@@ -47,45 +121,85 @@ func (a *App) BeforeClose(ctx context.Context) (prevent bool) {
 
 // Shutdown is called at application shutdown
 func (a *App) Shutdown(ctx context.Context) {
-	if a.watcher != nil {
-		a.watcher.Stop()
+	a.lifecycle.Lock()
+	a.mu.Lock()
+	if a.source != nil {
+		a.stopSources()
+	}
+	a.mu.Unlock()
+	a.lifecycle.Unlock()
+	if a.stopUpdates != nil {
+		a.stopUpdates()
 	}
+	a.CancelAnalysis()
 	fmt.Println("App shutdown")
 }
 
-// findLogFile ищет файл логов в стандартных местах
+// findLogFile ищет файл логов: сначала выбранный пользователем, затем по
+// путям из настроек
 func (a *App) findLogFile() string {
-	// Возможные пути для файла логов AOC
-	possiblePaths := []string{
-		// Стандартный путь для Windows
-		filepath.Join(os.Getenv("USERPROFILE"), "AppData", "Local", "AOC", "Saved", "Logs", "AOC.log"),
-		// Альтернативные пути
-		filepath.Join(os.Getenv("USERPROFILE"), "Documents", "AOC", "Logs", "AOC.log"),
-		filepath.Join(os.Getenv("USERPROFILE"), "AppData", "Roaming", "AOC", "Logs", "AOC.log"),
-		// Пользовательский путь (замените на свой путь к логам)
-		// "C:\\Your\\Custom\\Path\\To\\AOC\\Logs\\AOC.log",
-		// Локальный файл для тестирования
-		"AOC.log",
-	}
-
-	for _, path := range possiblePaths {
+	cfg := a.currentSettings()
+	if path := cfg.Logs.ChosenPath; path != "" {
+		if _, err := os.Stat(path); err == nil {
+			fmt.Printf("Using chosen log file: %s\n", path)
+			return path
+		}
+		fmt.Println("Chosen log file is not available:", path)
+	}
+
+	for _, pattern := range cfg.Logs.SearchPaths {
+		// Пути в настройках могут ссылаться на переменные окружения, например $USERPROFILE
+		path := filepath.FromSlash(os.ExpandEnv(pattern))
 		if _, err := os.Stat(path); err == nil {
 			fmt.Printf("Found log file: %s\n", path)
 			return path
 		}
 	}
 
+	// Ищем в профиле Windows, префиксах Proton и Lutris/Wine; берем самый свежий лог
+	if candidates := locator.New().Find(); len(candidates) > 0 {
+		fmt.Printf("Found log file (%s): %s\n", candidates[0].Source, candidates[0].Path)
+		return candidates[0].Path
+	}
+
 	fmt.Println("Log file not found in any standard location")
 	return ""
 }
 
-func (a *App) StartMonitoring() string {
+// GetLogCandidates возвращает все найденные файлы лога игры, от самого свежего
+func (a *App) GetLogCandidates() []LogCandidate {
+	candidates := locator.New().Find()
+
+	result := make([]LogCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		result = append(result, LogCandidate{
+			Path:    candidate.Path,
+			Source:  candidate.Source,
+			ModTime: candidate.ModTime.Format(time.RFC3339),
+		})
+	}
+	return result
+}
+
+// StartMonitoring начинает чтение лога. mode задает, с какого места читать
+// уже записанную часть файла: "end", "beginning", "timestamp" (с момента since
+// в формате RFC3339) или "session" (последний запуск игры). Пустой mode
+// означает режим из настроек; непустой сохраняется в настройках.
+func (a *App) StartMonitoring(mode string, since string) string {
 	fmt.Println("StartMonitoring called")
-	if a.watcher != nil {
+	a.lifecycle.Lock()
+	defer a.lifecycle.Unlock()
+	if a.monitoring() {
 		fmt.Println("Already monitoring")
 		return "Already monitoring"
 	}
 
+	if mode != "" {
+		if err := a.setStartMode(mode, since); err != nil {
+			return "Invalid start mode: " + err.Error()
+		}
+	}
+
 	// Ищем файл логов в стандартных местах
 	logPath := a.findLogFile()
 	if logPath == "" {
@@ -99,32 +213,89 @@ func (a *App) StartMonitoring() string {
 		return "Log file not found: " + logPath
 	}
 
-	a.watcher = watcher.NewWatcher(logPath, func(events []interface{}) {
-		fmt.Printf("Processing %d events\n", len(events))
-		for _, event := range events {
-			a.calculator.ProcessEvent(event)
+	if err := a.startSources([]source.Config{{Kind: "file", Path: logPath}}); err != nil {
+		return "Failed to start monitoring: " + err.Error()
+	}
+	return "Monitoring started"
+}
+
+// setStartMode сохраняет в настройках, с какого места читать лог
+func (a *App) setStartMode(mode, since string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	cfg := a.settings.Clone()
+	cfg.Monitoring = settings.Monitoring{StartMode: mode, StartTime: since}
+	if err := a.updateSettings(cfg); err != nil {
+		return err
+	}
+	if err := a.saveSettings(); err != nil {
+		fmt.Println("Failed to save settings:", err)
+	}
+	return nil
+}
+
+// startPosition проверяет настройки запуска и переводит их в позицию для watcher
+func startPosition(cfg settings.Monitoring) (watcher.StartPosition, error) {
+	mode, err := watcher.ParseStartMode(cfg.StartMode)
+	if err != nil {
+		return watcher.StartPosition{}, err
+	}
+
+	position := watcher.StartPosition{Mode: mode}
+	if mode == watcher.StartFromTime {
+		if position.Since, err = time.Parse(time.RFC3339, cfg.StartTime); err != nil {
+			return watcher.StartPosition{}, fmt.Errorf("invalid start time %q: %w", cfg.StartTime, err)
 		}
-	})
+	}
+	return position, nil
+}
 
-	if err := a.watcher.Start(); err != nil {
-		fmt.Println("Failed to start monitoring:", err)
-		return "Failed to start monitoring: " + err.Error()
+// FollowLogDirectory следит за каталогом логов и переключается на самый
+// новый файл лога. Если importBacklog = true, сначала обрабатываются
+// резервные логи AOC-backup-*.log из того же каталога.
+func (a *App) FollowLogDirectory(importBacklog bool) string {
+	fmt.Println("FollowLogDirectory called")
+	a.lifecycle.Lock()
+	defer a.lifecycle.Unlock()
+	if a.monitoring() {
+		fmt.Println("Already monitoring")
+		return "Already monitoring"
+	}
+
+	logPath := a.findLogFile()
+	if logPath == "" {
+		return "Log file not found in standard locations"
 	}
 
-	fmt.Println("Monitoring started successfully")
+	config := source.Config{Kind: "dir", Path: filepath.Dir(logPath), ImportBacklog: importBacklog}
+	if err := a.startSources([]source.Config{config}); err != nil {
+		return "Failed to start monitoring: " + err.Error()
+	}
 	return "Monitoring started"
 }
 
+// processEvents передает пачку событий из лога в калькулятор
+func (a *App) processEvents(events []parser.Event) {
+	fmt.Printf("Processing %d events\n", len(events))
+	a.calculator.ProcessEvents(events)
+	// Закрываем бой, если пачка была историей из уже записанного лога
+	a.calculator.Tick()
+}
+
 func (a *App) StopMonitoring() string {
 	fmt.Println("StopMonitoring called")
-	if a.watcher == nil {
-		fmt.Println("Watcher is nil, not monitoring")
+	a.lifecycle.Lock()
+	defer a.lifecycle.Unlock()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.source == nil {
+		fmt.Println("Source is nil, not monitoring")
 		return "Not monitoring"
 	}
 
-	fmt.Println("Stopping watcher...")
-	a.watcher.Stop()
-	a.watcher = nil
+	fmt.Println("Stopping event sources...")
+	a.stopSources()
 	fmt.Println("Monitoring stopped successfully")
 	return "Monitoring stopped"
 }
@@ -145,132 +316,509 @@ func (a *App) GetLogPath() string {
 	return a.findLogFile()
 }
 
-func (a *App) GetStats() map[string]interface{} {
-	session := a.calculator.GetSession()
+// ChooseLogFile открывает системный диалог выбора файла лога и делает
+// выбранный файл текущим, как SetLogPath
+func (a *App) ChooseLogFile() string {
+	path, err := a.openLogDialog("Choose AOC log file")
+	if err != nil {
+		fmt.Println("Failed to open file dialog:", err)
+		return "Failed to open file dialog: " + err.Error()
+	}
+	if path == "" {
+		return "No file chosen"
+	}
+
+	return a.SetLogPath(path)
+}
+
+// openLogDialog показывает системный диалог выбора файла лога. Пустой путь
+// означает, что пользователь закрыл диалог.
+func (a *App) openLogDialog(title string) (string, error) {
+	options := runtime.OpenDialogOptions{
+		Title: title,
+		Filters: []runtime.FileFilter{
+			{DisplayName: "AOC logs (*.log)", Pattern: "*.log"},
+			{DisplayName: "All files", Pattern: "*"},
+		},
+	}
+	if current := a.findLogFile(); current != "" {
+		options.DefaultDirectory = filepath.Dir(current)
+	}
+
+	return runtime.OpenFileDialog(a.ctx, options)
+}
 
-	critRate := 0.0
-	if session.Stats.TotalHits > 0 {
-		critRate = float64(session.Stats.CritHits) / float64(session.Stats.TotalHits) * 100
+// SetLogPath проверяет, что файл является логом игры, и сохраняет его
+// путь в настройках. При следующем запуске этот файл проверяется первым.
+func (a *App) SetLogPath(path string) string {
+	path = filepath.Clean(path)
+
+	ok, err := parser.IsCombatLog(path)
+	if err != nil {
+		return "Cannot read log file: " + err.Error()
+	}
+	if !ok {
+		return "Not an AOC combat log (no " + parser.CombatCategory + " lines): " + path
 	}
 
-	healingCritRate := 0.0
-	if session.Stats.TotalHealingHits > 0 {
-		healingCritRate = float64(session.Stats.CritHealing) / float64(session.Stats.TotalHealingHits) * 100
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	cfg := a.settings.Clone()
+	cfg.Logs.ChosenPath = path
+	if err := a.updateSettings(cfg); err != nil {
+		return "Invalid settings: " + err.Error()
 	}
 
-	stats := map[string]interface{}{
-		"maxDps":          session.DPSStats.MaxDPS,
-		"dps":             session.DPSStats.CurrentDPS,
-		"damage":          session.Stats.TotalDamage,
-		"hits":            session.Stats.TotalHits,
-		"crits":           session.Stats.CritHits,
-		"maxHps":          session.HPSStats.MaxHPS,
-		"hps":             session.HPSStats.CurrentHPS,
-		"healing":         session.Stats.TotalHealing,
-		"healingHits":     session.Stats.TotalHealingHits,
-		"healingCrits":    session.Stats.CritHealing,
-		"critRate":        critRate,
-		"healingCritRate": healingCritRate,
-		"kills":           session.Stats.TotalKills,
-		"duration":        time.Since(session.StartTime).Seconds(),
-		"isActive":        session.IsActive,
+	if err := a.saveSettings(); err != nil {
+		fmt.Println("Failed to save settings:", err)
+		return "Log path set, but not saved: " + err.Error()
 	}
+	return "Log path set to " + path
+}
 
-	fmt.Printf("Returning stats: %+v\n", stats)
-	return stats
+// GetAPIVersion возвращает версию формата данных, которые отдает App
+func (a *App) GetAPIVersion() int {
+	return APIVersion
 }
 
-func (a *App) GetAbilities() []map[string]interface{} {
+func (a *App) GetStats() Stats {
 	session := a.calculator.GetSession()
-	abilities := make([]*metrics.AbilityStats, 0, len(session.Abilities))
 
-	for _, ability := range session.Abilities {
+	return Stats{
+		Version:         APIVersion,
+		MaxDPS:          session.DPSStats.MaxDPS,
+		DPS:             session.DPSStats.CurrentDPS,
+		AvgDPS:          session.DPSStats.AvgDPS,
+		DPS15s:          session.DPSStats.Window15s,
+		DPS30s:          session.DPSStats.Window30s,
+		Damage:          session.Stats.TotalDamage,
+		Hits:            session.Stats.TotalHits,
+		Crits:           session.Stats.CritHits,
+		MaxHPS:          session.HPSStats.MaxHPS,
+		HPS:             session.HPSStats.CurrentHPS,
+		AvgHPS:          session.HPSStats.AvgHPS,
+		HPS15s:          session.HPSStats.Window15s,
+		HPS30s:          session.HPSStats.Window30s,
+		Healing:         session.Stats.TotalHealing,
+		HealingHits:     session.Stats.TotalHealingHits,
+		HealingCrits:    session.Stats.CritHealing,
+		CritRate:        percent(session.Stats.CritHits, session.Stats.TotalHits),
+		HealingCritRate: percent(session.Stats.CritHealing, session.Stats.TotalHealingHits),
+		Kills:           session.Stats.TotalKills,
+		Duration:        session.Elapsed().Seconds(),
+		IsActive:        session.IsActive,
+	}
+}
+
+func (a *App) GetAbilities() []AbilityRow {
+	return abilityRows(a.calculator.GetSession().Abilities)
+}
+
+// abilityRows преобразует статистику способностей в строки таблицы
+func abilityRows(stats map[string]*metrics.AbilityStats) []AbilityRow {
+	abilities := make([]*metrics.AbilityStats, 0, len(stats))
+
+	for _, ability := range stats {
 		if ability.Damage > 0 {
 			abilities = append(abilities, ability)
 		}
 	}
 
 	// Сортируем по урону
-	for i := 0; i < len(abilities); i++ {
-		for j := i + 1; j < len(abilities); j++ {
-			if abilities[i].Damage < abilities[j].Damage {
-				abilities[i], abilities[j] = abilities[j], abilities[i]
-			}
-		}
-	}
+	sort.Slice(abilities, func(i, j int) bool {
+		return abilities[i].Damage > abilities[j].Damage
+	})
 
-	result := make([]map[string]interface{}, 0, len(abilities))
+	result := make([]AbilityRow, 0, len(abilities))
 	for _, ability := range abilities {
-		critRate := 0.0
-		if ability.Hits > 0 {
-			critRate = float64(ability.Crits) / float64(ability.Hits) * 100
-		}
+		result = append(result, AbilityRow{
+			Name:            ability.Name,
+			Damage:          ability.Damage,
+			Healing:         ability.Healing,
+			Hits:            ability.Hits,
+			Crits:           ability.Crits,
+			CritRate:        percent(ability.Crits, ability.Hits),
+			HealingHits:     ability.HealingHits,
+			HealingCrits:    ability.CritHealing,
+			HealingCritRate: percent(ability.CritHealing, ability.HealingHits),
+			MinHit:          ability.MinHit,
+			MaxHit:          ability.MaxHit,
+			AvgHit:          ability.AverageHit(),
+			AvgNonCrit:      ability.AverageNonCrit(),
+			AvgCrit:         ability.AverageCrit(),
+			CritMultiplier:  ability.CritMultiplier(),
+		})
+	}
+
+	return result
+}
 
-		healingCritRate := 0.0
-		if ability.HealingHits > 0 {
-			healingCritRate = float64(ability.CritHealing) / float64(ability.HealingHits) * 100
+// GetAbilityDetail возвращает распределение ударов способности.
+// Пустой combatID означает статистику за всю сессию.
+func (a *App) GetAbilityDetail(combatID string, name string) *AbilityDetail {
+	abilities := a.calculator.GetSession().Abilities
+	if combatID != "" {
+		combat := a.findEncounter(combatID)
+		if combat == nil {
+			return nil
 		}
+		abilities = combat.Abilities
+	}
 
-		result = append(result, map[string]interface{}{
-			"name":            ability.Name,
-			"damage":          ability.Damage,
-			"healing":         ability.Healing,
-			"hits":            ability.Hits,
-			"crits":           ability.Crits,
-			"critRate":        critRate,
-			"healingHits":     ability.HealingHits,
-			"healingCrits":    ability.CritHealing,
-			"healingCritRate": healingCritRate,
+	ability, exists := abilities[name]
+	if !exists {
+		return nil
+	}
+
+	histogram := make([]HistogramBin, 0, metrics.DefaultHistogramBins)
+	for _, bin := range ability.Histogram(metrics.DefaultHistogramBins) {
+		histogram = append(histogram, HistogramBin{
+			Min:   bin.Min,
+			Max:   bin.Max,
+			Count: bin.Count,
 		})
 	}
 
-	return result
+	return &AbilityDetail{
+		Name:           ability.Name,
+		Damage:         ability.Damage,
+		Hits:           ability.Hits,
+		Crits:          ability.Crits,
+		MinHit:         ability.MinHit,
+		MaxHit:         ability.MaxHit,
+		AvgHit:         ability.AverageHit(),
+		AvgNonCrit:     ability.AverageNonCrit(),
+		AvgCrit:        ability.AverageCrit(),
+		CritMultiplier: ability.CritMultiplier(),
+		Histogram:      histogram,
+	}
 }
 
-func (a *App) GetTargets() []map[string]interface{} {
-	session := a.calculator.GetSession()
-	targets := make([]*metrics.TargetStats, 0, len(session.Targets))
+func (a *App) GetTargets() []TargetRow {
+	return targetRows(a.calculator.GetSession().Targets)
+}
+
+// targetRows преобразует статистику целей в строки таблицы
+func targetRows(stats map[string]*metrics.TargetStats) []TargetRow {
+	targets := make([]*metrics.TargetStats, 0, len(stats))
 
-	for _, target := range session.Targets {
+	for _, target := range stats {
 		if target.Damage > 0 {
 			targets = append(targets, target)
 		}
 	}
 
 	// Сортируем по урону
-	for i := 0; i < len(targets); i++ {
-		for j := i + 1; j < len(targets); j++ {
-			if targets[i].Damage < targets[j].Damage {
-				targets[i], targets[j] = targets[j], targets[i]
-			}
-		}
-	}
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].Damage > targets[j].Damage
+	})
 
-	result := make([]map[string]interface{}, 0, len(targets))
+	result := make([]TargetRow, 0, len(targets))
 	for _, target := range targets {
-		critRate := 0.0
-		if target.Hits > 0 {
-			critRate = float64(target.Crits) / float64(target.Hits) * 100
-		}
+		result = append(result, TargetRow{
+			Name:            target.Name,
+			Damage:          target.Damage,
+			Healing:         target.Healing,
+			Hits:            target.Hits,
+			Crits:           target.Crits,
+			CritRate:        percent(target.Crits, target.Hits),
+			Kills:           target.Kills,
+			HealingHits:     target.HealingHits,
+			HealingCrits:    target.CritHealing,
+			HealingCritRate: percent(target.CritHealing, target.HealingHits),
+		})
+	}
+
+	return result
+}
+
+func (a *App) GetDamageTaken() DamageTaken {
+	session := a.calculator.GetSession()
+
+	return DamageTaken{
+		Damage:   session.DamageTaken.TotalDamage,
+		Hits:     session.DamageTaken.TotalHits,
+		Crits:    session.DamageTaken.CritHits,
+		CritRate: percent(session.DamageTaken.CritHits, session.DamageTaken.TotalHits),
+		DTPS:     session.DamageTaken.CurrentDTPS,
+		MaxDTPS:  session.DamageTaken.MaxDTPS,
+	}
+}
+
+func (a *App) GetDamageTakenBySource() []DamageTakenRow {
+	session := a.calculator.GetSession()
+	sources := make([]*metrics.SourceStats, 0, len(session.TakenBySource))
+
+	for _, source := range session.TakenBySource {
+		sources = append(sources, source)
+	}
+
+	// Сортируем по урону
+	sort.Slice(sources, func(i, j int) bool {
+		return sources[i].Damage > sources[j].Damage
+	})
+
+	result := make([]DamageTakenRow, 0, len(sources))
+	for _, source := range sources {
+		result = append(result, DamageTakenRow{
+			Name:     source.Name,
+			Damage:   source.Damage,
+			Hits:     source.Hits,
+			Crits:    source.Crits,
+			CritRate: percent(source.Crits, source.Hits),
+		})
+	}
+
+	return result
+}
+
+func (a *App) GetDamageTakenByAbility() []DamageTakenRow {
+	session := a.calculator.GetSession()
+	abilities := make([]*metrics.AbilityStats, 0, len(session.TakenByAbility))
+
+	for _, ability := range session.TakenByAbility {
+		abilities = append(abilities, ability)
+	}
 
-		healingCritRate := 0.0
-		if target.HealingHits > 0 {
-			healingCritRate = float64(target.CritHealing) / float64(target.HealingHits) * 100
+	// Сортируем по урону
+	sort.Slice(abilities, func(i, j int) bool {
+		return abilities[i].Damage > abilities[j].Damage
+	})
+
+	result := make([]DamageTakenRow, 0, len(abilities))
+	for _, ability := range abilities {
+		result = append(result, DamageTakenRow{
+			Name:     ability.Name,
+			Damage:   ability.Damage,
+			Hits:     ability.Hits,
+			Crits:    ability.Crits,
+			CritRate: percent(ability.Crits, ability.Hits),
+		})
+	}
+
+	return result
+}
+
+// GetEncounters возвращает список боев сессии: завершенные и текущий
+func (a *App) GetEncounters() []EncounterSummary {
+	encounters := a.calculator.GetEncounters()
+
+	result := make([]EncounterSummary, 0, len(encounters))
+	for _, combat := range encounters {
+		result = append(result, encounterSummary(combat))
+	}
+
+	return result
+}
+
+// GetEncounter возвращает полную статистику одного боя
+func (a *App) GetEncounter(id string) *Encounter {
+	combat := a.findEncounter(id)
+	if combat == nil {
+		return nil
+	}
+
+	return &Encounter{
+		Summary:   encounterSummary(combat),
+		Abilities: abilityRows(combat.Abilities),
+		Targets:   targetRows(combat.Targets),
+	}
+}
+
+// GetTimeline возвращает посекундный ряд урона и исцеления для боя
+func (a *App) GetTimeline(combatID string) []TimelinePoint {
+	combat := a.findEncounter(combatID)
+	if combat == nil {
+		return nil
+	}
+
+	result := make([]TimelinePoint, 0, len(combat.Timeline))
+	for _, bucket := range combat.Timeline {
+		result = append(result, TimelinePoint{
+			Second:      bucket.Second,
+			Damage:      bucket.Damage,
+			Healing:     bucket.Healing,
+			DamageTaken: bucket.DamageTaken,
+		})
+	}
+
+	return result
+}
+
+// GetBuffs возвращает аптайм баффов и дебаффов за бой
+func (a *App) GetBuffs(combatID string) []BuffRow {
+	combat := a.findEncounter(combatID)
+	if combat == nil {
+		return nil
+	}
+
+	result := make([]BuffRow, 0, len(combat.Buffs))
+	for _, buff := range combat.Buffs {
+		result = append(result, BuffRow{
+			Name:         buff.Name,
+			Target:       buff.Target,
+			IsDebuff:     buff.IsDebuff,
+			Uptime:       combat.BuffUptime(buff),
+			Applications: buff.Applications,
+			Refreshes:    buff.Refreshes,
+			AvgDuration:  buff.AverageDuration().Seconds(),
+		})
+	}
+
+	// Сортируем по аптайму
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Uptime > result[j].Uptime
+	})
+
+	return result
+}
+
+// GetBuffAttribution возвращает урон, нанесенный под действием каждого баффа
+// и дебаффа, и прирост DPS по сравнению с окнами без него
+func (a *App) GetBuffAttribution(combatID string) []BuffAttribution {
+	combat := a.findEncounter(combatID)
+	if combat == nil {
+		return nil
+	}
+
+	result := make([]BuffAttribution, 0, len(combat.Buffs))
+	for _, buff := range combat.Buffs {
+		attribution := combat.BuffAttribution(buff)
+
+		abilities := make([]BuffAbilityDamage, 0, len(buff.AbilityDamage))
+		for name, damage := range buff.AbilityDamage {
+			total := 0
+			if ability, exists := combat.Abilities[name]; exists {
+				total = ability.Damage
+			}
+			abilities = append(abilities, BuffAbilityDamage{
+				Name:        name,
+				Damage:      damage,
+				TotalDamage: total,
+			})
 		}
+		sort.Slice(abilities, func(i, j int) bool {
+			return abilities[i].Damage > abilities[j].Damage
+		})
 
-		result = append(result, map[string]interface{}{
-			"name":            target.Name,
-			"damage":          target.Damage,
-			"healing":         target.Healing,
-			"hits":            target.Hits,
-			"crits":           target.Crits,
-			"critRate":        critRate,
-			"kills":           target.Kills,
-			"healingHits":     target.HealingHits,
-			"healingCrits":    target.CritHealing,
-			"healingCritRate": healingCritRate,
+		result = append(result, BuffAttribution{
+			Name:           buff.Name,
+			Target:         buff.Target,
+			IsDebuff:       buff.IsDebuff,
+			ActiveTime:     attribution.ActiveTime.Seconds(),
+			InactiveTime:   attribution.InactiveTime.Seconds(),
+			DamageActive:   attribution.DamageActive,
+			DamageInactive: attribution.DamageInactive,
+			DPSActive:      attribution.DPSActive,
+			DPSInactive:    attribution.DPSInactive,
+			Gain:           attribution.Gain,
+			Abilities:      abilities,
 		})
 	}
 
+	// Сортируем по урону под баффом
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].DamageActive > result[j].DamageActive
+	})
+
 	return result
 }
+
+// encounterSummary собирает итоговые показатели боя
+func encounterSummary(combat *metrics.Combat) EncounterSummary {
+	duration := combat.Elapsed()
+
+	endTime := ""
+	if !combat.IsActive {
+		endTime = combat.EndTime.Format(time.RFC3339)
+	}
+
+	dps, hps, dtps := 0.0, 0.0, 0.0
+	if duration > 0 {
+		dps = float64(combat.Stats.TotalDamage) / duration.Seconds()
+		hps = float64(combat.Stats.TotalHealing) / duration.Seconds()
+		dtps = float64(combat.DamageTaken.TotalDamage) / duration.Seconds()
+	}
+
+	return EncounterSummary{
+		ID:          combat.ID,
+		StartTime:   combat.StartTime.Format(time.RFC3339),
+		EndTime:     endTime,
+		Duration:    duration.Seconds(),
+		IsActive:    combat.IsActive,
+		Damage:      combat.Stats.TotalDamage,
+		Hits:        combat.Stats.TotalHits,
+		Crits:       combat.Stats.CritHits,
+		CritRate:    percent(combat.Stats.CritHits, combat.Stats.TotalHits),
+		Healing:     combat.Stats.TotalHealing,
+		Kills:       combat.Stats.TotalKills,
+		DamageTaken: combat.DamageTaken.TotalDamage,
+		DPS:         dps,
+		HPS:         hps,
+		DTPS:        dtps,
+	}
+}
+
+// percent возвращает долю part от total в процентах
+func percent(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
+}
+
+// applySegmentation применяет политику разбиения лога на бои к калькулятору
+func (a *App) applySegmentation(cfg settings.Segmentation) error {
+	timeout := time.Duration(cfg.IdleTimeoutSeconds) * time.Second
+	policy, err := metrics.NewSegmentationPolicy(metrics.SegmentationMode(cfg.Mode), timeout)
+	if err != nil {
+		return err
+	}
+	a.calculator.SetSegmentationPolicy(policy)
+	return nil
+}
+
+// GetSegmentation возвращает текущую политику разбиения лога на бои
+func (a *App) GetSegmentation() Segmentation {
+	cfg := a.currentSettings()
+	return Segmentation{
+		Mode:        cfg.Segmentation.Mode,
+		IdleTimeout: cfg.Segmentation.IdleTimeoutSeconds,
+	}
+}
+
+// SetSegmentation выбирает политику разбиения лога на бои и сохраняет ее в настройках.
+// mode: "idle", "kills" или "manual"; idleTimeout в секундах.
+func (a *App) SetSegmentation(mode string, idleTimeout int) string {
+	if idleTimeout <= 0 {
+		idleTimeout = int(metrics.DefaultIdleTimeout / time.Second)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	cfg := a.settings.Clone()
+	cfg.Segmentation = settings.Segmentation{Mode: mode, IdleTimeoutSeconds: idleTimeout}
+	if err := a.updateSettings(cfg); err != nil {
+		return "Invalid segmentation: " + err.Error()
+	}
+
+	if err := a.saveSettings(); err != nil {
+		fmt.Println("Failed to save settings:", err)
+		return "Segmentation applied, but not saved: " + err.Error()
+	}
+	return "Segmentation set to " + mode
+}
+
+// StartCombat вручную начинает новый бой (для режима "manual")
+func (a *App) StartCombat() string {
+	a.calculator.StartCombat()
+	return "Combat started"
+}
+
+// StopCombat вручную завершает текущий бой (для режима "manual")
+func (a *App) StopCombat() string {
+	a.calculator.StopCombat()
+	return "Combat stopped"
+}
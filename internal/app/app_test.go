@@ -0,0 +1,115 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"aocdpsmetr/internal/parser"
+	"aocdpsmetr/internal/source"
+)
+
+// writeLog создает лог из n строк урона
+func writeLog(t *testing.T, n int) string {
+	t.Helper()
+	var log strings.Builder
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		timestamp := start.Add(time.Duration(i) * time.Second).Format("2006-01-02T15:04:05.000Z")
+		fmt.Fprintf(&log, `{"frame":%d,"category":"LogAoC_CombatLog","verbosity":"Display","message":"[x][  0]LogAoC_CombatLog: Display: CombatLog: Player hit: 100 damage dealt to Mob - Slash","timestamp":"%s"}`+"\n", i+1, timestamp)
+	}
+
+	path := filepath.Join(t.TempDir(), "AOC.log")
+	if err := os.WriteFile(path, []byte(log.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// newTestApp создает App с настройками во временном каталоге
+func newTestApp(t *testing.T) *App {
+	t.Helper()
+	config := t.TempDir()
+	t.Setenv("HOME", config)
+	t.Setenv("XDG_CONFIG_HOME", config)
+	t.Setenv("APPDATA", config)
+	return NewApp()
+}
+
+// Запускать с -race: привязки фронтенда вызываются из разных горутин
+func TestConcurrentBindings(t *testing.T) {
+	a := newTestApp(t)
+	log := writeLog(t, 200)
+
+	var wg sync.WaitGroup
+	run := func(fn func(i int)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				fn(i)
+			}
+		}()
+	}
+
+	run(func(i int) {
+		a.StartSources([]source.Config{{Kind: "replay", Path: log, Speed: "instant"}})
+		a.GetReplayState()
+		a.StopMonitoring()
+	})
+	run(func(i int) {
+		a.StartReplay(log, "16x")
+		a.PauseReplay()
+		a.StopReplay()
+	})
+	run(func(i int) {
+		a.SetUpdateRate(1 + i%30)
+		a.SaveSettings(a.GetSettings())
+	})
+	run(func(i int) {
+		a.SetSegmentation("idle", 1+i%20)
+		a.GetSegmentation()
+		a.GetUpdateRate()
+		a.GetLogPath()
+	})
+	wg.Wait()
+
+	a.Shutdown(context.Background())
+	if a.source != nil {
+		t.Error("source is still running after shutdown")
+	}
+}
+
+func TestMergedSourceResetKeepsSession(t *testing.T) {
+	a := newTestApp(t)
+	stdin := func() source.EventSource {
+		src, err := source.New(source.Config{Kind: "stdin", Input: strings.NewReader("")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return src
+	}
+	single, merged := stdin(), source.Merge(stdin(), stdin())
+
+	events, err := parser.NewParser().ParseFile(writeLog(t, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.handleBatch(merged, source.Batch{Events: events})
+
+	// Перемотка и конец данных одного из объединенных источников не трогают сессию
+	a.handleBatch(merged, source.Batch{Reset: true, Finished: true})
+	if damage := a.calculator.GetSession().Stats.TotalDamage; damage != 300 {
+		t.Fatalf("TotalDamage = %d after a merged reset, want 300", damage)
+	}
+
+	a.handleBatch(single, source.Batch{Reset: true})
+	if damage := a.calculator.GetSession().Stats.TotalDamage; damage != 0 {
+		t.Errorf("TotalDamage = %d after a reset, want 0", damage)
+	}
+}
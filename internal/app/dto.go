@@ -0,0 +1,235 @@
+package app
+
+// APIVersion версия формата данных, которые App отдает фронтенду и другим
+// потребителям. Увеличивается при несовместимых изменениях структур ниже.
+const APIVersion = 1
+
+// Stats общая статистика сессии
+type Stats struct {
+	Version         int     `json:"version"`
+	MaxDPS          float64 `json:"maxDps"`
+	DPS             float64 `json:"dps"`
+	AvgDPS          float64 `json:"avgDps"`
+	DPS15s          float64 `json:"dps15s"`
+	DPS30s          float64 `json:"dps30s"`
+	Damage          int     `json:"damage"`
+	Hits            int     `json:"hits"`
+	Crits           int     `json:"crits"`
+	MaxHPS          float64 `json:"maxHps"`
+	HPS             float64 `json:"hps"`
+	AvgHPS          float64 `json:"avgHps"`
+	HPS15s          float64 `json:"hps15s"`
+	HPS30s          float64 `json:"hps30s"`
+	Healing         int     `json:"healing"`
+	HealingHits     int     `json:"healingHits"`
+	HealingCrits    int     `json:"healingCrits"`
+	CritRate        float64 `json:"critRate"`
+	HealingCritRate float64 `json:"healingCritRate"`
+	Kills           int     `json:"kills"`
+	Duration        float64 `json:"duration"` // Секунды
+	IsActive        bool    `json:"isActive"`
+}
+
+// AbilityRow строка таблицы способностей
+type AbilityRow struct {
+	Name            string  `json:"name"`
+	Damage          int     `json:"damage"`
+	Healing         int     `json:"healing"`
+	Hits            int     `json:"hits"`
+	Crits           int     `json:"crits"`
+	CritRate        float64 `json:"critRate"`
+	HealingHits     int     `json:"healingHits"`
+	HealingCrits    int     `json:"healingCrits"`
+	HealingCritRate float64 `json:"healingCritRate"`
+	MinHit          int     `json:"minHit"`
+	MaxHit          int     `json:"maxHit"`
+	AvgHit          float64 `json:"avgHit"`
+	AvgNonCrit      float64 `json:"avgNonCrit"`
+	AvgCrit         float64 `json:"avgCrit"`
+	CritMultiplier  float64 `json:"critMultiplier"`
+}
+
+// HistogramBin столбец гистограммы размеров ударов
+type HistogramBin struct {
+	Min   int `json:"min"`
+	Max   int `json:"max"`
+	Count int `json:"count"`
+}
+
+// AbilityDetail распределение ударов способности
+type AbilityDetail struct {
+	Name           string         `json:"name"`
+	Damage         int            `json:"damage"`
+	Hits           int            `json:"hits"`
+	Crits          int            `json:"crits"`
+	MinHit         int            `json:"minHit"`
+	MaxHit         int            `json:"maxHit"`
+	AvgHit         float64        `json:"avgHit"`
+	AvgNonCrit     float64        `json:"avgNonCrit"`
+	AvgCrit        float64        `json:"avgCrit"`
+	CritMultiplier float64        `json:"critMultiplier"`
+	Histogram      []HistogramBin `json:"histogram"`
+}
+
+// TargetRow строка таблицы целей
+type TargetRow struct {
+	Name            string  `json:"name"`
+	Damage          int     `json:"damage"`
+	Healing         int     `json:"healing"`
+	Hits            int     `json:"hits"`
+	Crits           int     `json:"crits"`
+	CritRate        float64 `json:"critRate"`
+	Kills           int     `json:"kills"`
+	HealingHits     int     `json:"healingHits"`
+	HealingCrits    int     `json:"healingCrits"`
+	HealingCritRate float64 `json:"healingCritRate"`
+}
+
+// DamageTaken общая статистика полученного урона
+type DamageTaken struct {
+	Damage   int     `json:"damage"`
+	Hits     int     `json:"hits"`
+	Crits    int     `json:"crits"`
+	CritRate float64 `json:"critRate"`
+	DTPS     float64 `json:"dtps"`
+	MaxDTPS  float64 `json:"maxDtps"`
+}
+
+// DamageTakenRow строка таблицы полученного урона по источнику или способности
+type DamageTakenRow struct {
+	Name     string  `json:"name"`
+	Damage   int     `json:"damage"`
+	Hits     int     `json:"hits"`
+	Crits    int     `json:"crits"`
+	CritRate float64 `json:"critRate"`
+}
+
+// EncounterSummary итоговые показатели боя
+type EncounterSummary struct {
+	ID          string  `json:"id"`
+	StartTime   string  `json:"startTime"` // RFC3339
+	EndTime     string  `json:"endTime"`   // RFC3339, пусто для активного боя
+	Duration    float64 `json:"duration"`  // Секунды
+	IsActive    bool    `json:"isActive"`
+	Damage      int     `json:"damage"`
+	Hits        int     `json:"hits"`
+	Crits       int     `json:"crits"`
+	CritRate    float64 `json:"critRate"`
+	Healing     int     `json:"healing"`
+	Kills       int     `json:"kills"`
+	DamageTaken int     `json:"damageTaken"`
+	DPS         float64 `json:"dps"`
+	HPS         float64 `json:"hps"`
+	DTPS        float64 `json:"dtps"`
+}
+
+// Encounter полная статистика одного боя
+type Encounter struct {
+	Summary   EncounterSummary `json:"summary"`
+	Abilities []AbilityRow     `json:"abilities"`
+	Targets   []TargetRow      `json:"targets"`
+}
+
+// TimelinePoint значения за одну секунду боя
+type TimelinePoint struct {
+	Second      int `json:"second"`
+	Damage      int `json:"damage"`
+	Healing     int `json:"healing"`
+	DamageTaken int `json:"damageTaken"`
+}
+
+// BuffRow аптайм баффа или дебаффа за бой
+type BuffRow struct {
+	Name         string  `json:"name"`
+	Target       string  `json:"target"`
+	IsDebuff     bool    `json:"isDebuff"`
+	Uptime       float64 `json:"uptime"` // Проценты
+	Applications int     `json:"applications"`
+	Refreshes    int     `json:"refreshes"`
+	AvgDuration  float64 `json:"avgDuration"` // Секунды
+}
+
+// BuffAbilityDamage урон способности под действием баффа
+type BuffAbilityDamage struct {
+	Name        string `json:"name"`
+	Damage      int    `json:"damage"`
+	TotalDamage int    `json:"totalDamage"`
+}
+
+// BuffAttribution урон под действием баффа и прирост DPS
+type BuffAttribution struct {
+	Name           string              `json:"name"`
+	Target         string              `json:"target"`
+	IsDebuff       bool                `json:"isDebuff"`
+	ActiveTime     float64             `json:"activeTime"`   // Секунды
+	InactiveTime   float64             `json:"inactiveTime"` // Секунды
+	DamageActive   int                 `json:"damageActive"`
+	DamageInactive int                 `json:"damageInactive"`
+	DPSActive      float64             `json:"dpsActive"`
+	DPSInactive    float64             `json:"dpsInactive"`
+	Gain           float64             `json:"gain"` // Проценты
+	Abilities      []BuffAbilityDamage `json:"abilities"`
+}
+
+// Segmentation текущая политика разбиения лога на бои
+type Segmentation struct {
+	Mode        string `json:"mode"`
+	IdleTimeout int    `json:"idleTimeout"` // Секунды
+}
+
+// LogCandidate найденный файл лога игры
+type LogCandidate struct {
+	Path    string `json:"path"`
+	Source  string `json:"source"`  // "windows", "proton", "lutris" или "wine"
+	ModTime string `json:"modTime"` // RFC3339
+}
+
+// StatsUpdate данные события EventStatsUpdate
+type StatsUpdate struct {
+	Version   int          `json:"version"`
+	Stats     Stats        `json:"stats"`
+	Abilities []AbilityRow `json:"abilities"`
+	Targets   []TargetRow  `json:"targets"`
+}
+
+// CombatStarted данные события EventCombatStarted
+type CombatStarted struct {
+	ID        string `json:"id"`
+	StartTime string `json:"startTime"` // RFC3339
+}
+
+// LogRotated данные события EventLogRotated
+type LogRotated struct {
+	Reason string `json:"reason"`
+}
+
+// MonitoringError данные события EventMonitoringError
+type MonitoringError struct {
+	Error string `json:"error"`
+}
+
+// AnalysisProgress данные события EventAnalysisProgress
+type AnalysisProgress struct {
+	Path    string  `json:"path"`
+	Read    int64   `json:"read"`  // Байты
+	Total   int64   `json:"total"` // Байты
+	Percent float64 `json:"percent"`
+}
+
+// AnalysisResult бои проанализированного файла, данные события EventAnalysisDone
+type AnalysisResult struct {
+	Path       string             `json:"path"`
+	Encounters []EncounterSummary `json:"encounters"`
+	Error      string             `json:"error,omitempty"`
+}
+
+// ReplayState состояние воспроизведения записанного лога
+type ReplayState struct {
+	Active   bool   `json:"active"`
+	Speed    string `json:"speed"` // "1x", "4x", "16x" или "instant"
+	Paused   bool   `json:"paused"`
+	Finished bool   `json:"finished"`
+	Position string `json:"position"` // RFC3339, текущее время воспроизведения
+	Start    string `json:"start"`    // RFC3339, первое событие лога
+	End      string `json:"end"`      // RFC3339, последнее событие лога
+}
@@ -0,0 +1,139 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"aocdpsmetr/internal/metrics"
+	"aocdpsmetr/internal/settings"
+)
+
+// События, которые App отправляет во фронтенд через runtime.EventsEmit
+const (
+	// EventStatsUpdate - новая статистика, StatsUpdate
+	EventStatsUpdate = "stats:update"
+	// EventCombatStarted - начался бой, CombatStarted
+	EventCombatStarted = "combat:started"
+	// EventCombatEnded - бой завершен, EncounterSummary
+	EventCombatEnded = "combat:ended"
+	// EventLogRotated - файл лога обрезан или заменен, LogRotated
+	EventLogRotated = "log:rotated"
+	// EventMonitoringError - ошибка чтения лога, MonitoringError
+	EventMonitoringError = "monitoring:error"
+	// EventAnalysisProgress - ход анализа файла, AnalysisProgress
+	EventAnalysisProgress = "analysis:progress"
+	// EventAnalysisDone - анализ файла завершен или отменен, AnalysisResult
+	EventAnalysisDone = "analysis:done"
+	// EventReplayFinished - воспроизведение дошло до конца лога, ReplayState
+	EventReplayFinished = "replay:finished"
+)
+
+// emit отправляет событие во фронтенд. До запуска Wails событие пропускается.
+func (a *App) emit(name string, data ...interface{}) {
+	if a.ctx == nil {
+		return
+	}
+	runtime.EventsEmit(a.ctx, name, data...)
+}
+
+// pushUpdates отправляет статистику во фронтенд, когда она изменилась, но не
+// чаще заданной частоты. Заодно завершает бой, после которого событий больше
+// не было, чтобы текущий DPS не зависал на последнем значении.
+func (a *App) pushUpdates(ctx context.Context) {
+	interval := a.updateInterval()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.calculator.Tick()
+			if a.dirty.Swap(false) {
+				a.emit(EventStatsUpdate, a.statsUpdate())
+			}
+
+			// Частоту могли изменить в настройках
+			if next := a.updateInterval(); next != interval {
+				interval = next
+				ticker.Reset(interval)
+			}
+		}
+	}
+}
+
+// statsUpdate собирает данные для события EventStatsUpdate
+func (a *App) statsUpdate() StatsUpdate {
+	return StatsUpdate{
+		Version:   APIVersion,
+		Stats:     a.GetStats(),
+		Abilities: a.GetAbilities(),
+		Targets:   a.GetTargets(),
+	}
+}
+
+// updateInterval возвращает минимальный интервал между обновлениями
+func (a *App) updateInterval() time.Duration {
+	if interval := time.Duration(a.updateEvery.Load()); interval > 0 {
+		return interval
+	}
+	// Настройки не загружались - используем частоту по умолчанию
+	return time.Second / time.Duration(settings.Default().Updates.MaxPerSecond)
+}
+
+// applyUpdateRate задает максимальную частоту обновлений статистики
+func (a *App) applyUpdateRate(perSecond int) {
+	a.updateEvery.Store(int64(time.Second / time.Duration(perSecond)))
+}
+
+// GetUpdateRate возвращает максимальное число обновлений статистики в секунду
+func (a *App) GetUpdateRate() int {
+	return a.currentSettings().Updates.MaxPerSecond
+}
+
+// SetUpdateRate задает максимальное число обновлений статистики в секунду
+// и сохраняет его в настройках
+func (a *App) SetUpdateRate(perSecond int) string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	cfg := a.settings.Clone()
+	cfg.Updates.MaxPerSecond = perSecond
+	if err := a.updateSettings(cfg); err != nil {
+		return "Invalid update rate: " + err.Error()
+	}
+
+	if err := a.saveSettings(); err != nil {
+		fmt.Println("Failed to save settings:", err)
+		return "Update rate applied, but not saved: " + err.Error()
+	}
+	return fmt.Sprintf("Update rate set to %d per second", perSecond)
+}
+
+// onCombatStarted вызывается калькулятором при начале боя
+func (a *App) onCombatStarted(combat *metrics.Combat) {
+	a.emit(EventCombatStarted, CombatStarted{
+		ID:        combat.ID,
+		StartTime: combat.StartTime.Format(time.RFC3339),
+	})
+}
+
+// onCombatEnded вызывается калькулятором при завершении боя
+func (a *App) onCombatEnded(combat *metrics.Combat) {
+	a.emit(EventCombatEnded, encounterSummary(combat))
+}
+
+// onLogRotated вызывается, когда игра обрезала или заменила файл лога
+func (a *App) onLogRotated(reason string) {
+	fmt.Println("Log rotated:", reason)
+	a.emit(EventLogRotated, LogRotated{Reason: reason})
+}
+
+// onMonitoringError вызывается при ошибке чтения лога
+func (a *App) onMonitoringError(err error) {
+	a.emit(EventMonitoringError, MonitoringError{Error: err.Error()})
+}
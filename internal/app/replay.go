@@ -0,0 +1,151 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"aocdpsmetr/internal/parser"
+	"aocdpsmetr/internal/replay"
+	"aocdpsmetr/internal/source"
+)
+
+// StartReplay воспроизводит записанный лог вместо живого. События идут в
+// калькулятор тем же путем, что и из живого лога, с исходными паузами между ними.
+// speed: "1x", "4x", "16x" или "instant". Пустой path означает текущий файл лога.
+func (a *App) StartReplay(path string, speed string) string {
+	a.lifecycle.Lock()
+	defer a.lifecycle.Unlock()
+
+	if a.monitoring() {
+		return "Already monitoring"
+	}
+
+	if _, err := replay.ParseSpeed(speed); err != nil {
+		return "Invalid replay speed: " + err.Error()
+	}
+
+	if path == "" {
+		path = a.findLogFile()
+		if path == "" {
+			return "Log file not found in standard locations"
+		}
+	}
+	path = filepath.Clean(path)
+
+	ok, err := parser.IsCombatLog(path)
+	if err != nil {
+		return "Cannot read log file: " + err.Error()
+	}
+	if !ok {
+		return "Not an AOC combat log (no " + parser.CombatCategory + " lines): " + path
+	}
+
+	a.calculator.ResetSession()
+	if err := a.startSources([]source.Config{{Kind: "replay", Path: path, Speed: speed}}); err != nil {
+		return "Failed to start replay: " + err.Error()
+	}
+	return "Replay started: " + path
+}
+
+// StopReplay останавливает воспроизведение. Статистика остается до сброса.
+func (a *App) StopReplay() string {
+	a.lifecycle.Lock()
+	defer a.lifecycle.Unlock()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.source == nil {
+		return "Not replaying"
+	}
+	if _, ok := source.Find[source.Playback](a.source); !ok {
+		return "Not replaying"
+	}
+
+	a.stopSources()
+	return "Replay stopped"
+}
+
+// PauseReplay приостанавливает воспроизведение
+func (a *App) PauseReplay() string {
+	playback, ok := a.playback()
+	if !ok {
+		return "Not replaying"
+	}
+	playback.Pause()
+	return "Replay paused"
+}
+
+// ResumeReplay продолжает воспроизведение после паузы
+func (a *App) ResumeReplay() string {
+	playback, ok := a.playback()
+	if !ok {
+		return "Not replaying"
+	}
+	playback.Resume()
+	return "Replay resumed"
+}
+
+// SetReplaySpeed меняет скорость воспроизведения: "1x", "4x", "16x" или "instant"
+func (a *App) SetReplaySpeed(speed string) string {
+	playback, ok := a.playback()
+	if !ok {
+		return "Not replaying"
+	}
+
+	value, err := replay.ParseSpeed(speed)
+	if err != nil {
+		return "Invalid replay speed: " + err.Error()
+	}
+	playback.SetSpeed(value)
+	return "Replay speed set to " + value.String()
+}
+
+// SeekReplay переходит к моменту at в формате RFC3339. Статистика
+// сбрасывается, и воспроизведение продолжается с первого события после at.
+func (a *App) SeekReplay(at string) string {
+	playback, ok := a.playback()
+	if !ok {
+		return "Not replaying"
+	}
+
+	position, err := time.Parse(time.RFC3339, at)
+	if err != nil {
+		return fmt.Sprintf("Invalid replay position %q: %v", at, err)
+	}
+	playback.Seek(position)
+	return "Replay position set to " + position.Format(time.RFC3339)
+}
+
+// GetReplayState возвращает состояние воспроизведения
+func (a *App) GetReplayState() ReplayState {
+	playback, ok := a.playback()
+	if !ok {
+		return ReplayState{}
+	}
+	return replayState(playback.State())
+}
+
+// playback возвращает текущее воспроизведение среди источников событий
+func (a *App) playback() (source.Playback, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.source == nil {
+		return nil, false
+	}
+	return source.Find[source.Playback](a.source)
+}
+
+// replayState переводит состояние воспроизведения в DTO
+func replayState(state replay.State) ReplayState {
+	return ReplayState{
+		Active:   true,
+		Speed:    state.Speed.String(),
+		Paused:   state.Paused,
+		Finished: state.Finished,
+		Position: state.Position.Format(time.RFC3339),
+		Start:    state.Start.Format(time.RFC3339),
+		End:      state.End.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"aocdpsmetr/internal/settings"
+	"aocdpsmetr/internal/source"
+)
+
+// GetSettings возвращает текущие настройки
+func (a *App) GetSettings() settings.Settings {
+	return *a.currentSettings().Clone()
+}
+
+// currentSettings возвращает текущие настройки. Они не меняются на месте:
+// updateSettings заменяет их целиком, поэтому результат можно читать без блокировки.
+func (a *App) currentSettings() *settings.Settings {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.settings
+}
+
+// SaveSettings проверяет настройки, сразу применяет их к калькулятору и
+// текущему мониторингу и сохраняет в файл. Пути поиска лога и начальная
+// позиция действуют со следующего запуска мониторинга.
+func (a *App) SaveSettings(cfg settings.Settings) string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.updateSettings(&cfg); err != nil {
+		return "Invalid settings: " + err.Error()
+	}
+
+	if err := a.saveSettings(); err != nil {
+		fmt.Println("Failed to save settings:", err)
+		return "Settings applied, but not saved: " + err.Error()
+	}
+	return "Settings saved"
+}
+
+// updateSettings проверяет настройки и делает их текущими. Вызывается под a.mu.
+func (a *App) updateSettings(cfg *settings.Settings) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if err := a.applySegmentation(cfg.Segmentation); err != nil {
+		return err
+	}
+
+	a.applyUpdateRate(cfg.Updates.MaxPerSecond)
+	if a.source != nil {
+		setPollInterval(a.source, pollInterval(cfg))
+	}
+
+	a.settings = cfg.Clone()
+	return nil
+}
+
+// setPollInterval задает интервал проверки файла всем источникам, которые читают файлы
+func setPollInterval(src source.EventSource, interval time.Duration) {
+	source.Walk(src, func(src source.EventSource) {
+		if poller, ok := src.(source.Poller); ok {
+			poller.SetPollInterval(interval)
+		}
+	})
+}
+
+// pollInterval возвращает интервал проверки файла лога из настроек
+func pollInterval(cfg *settings.Settings) time.Duration {
+	return time.Duration(cfg.Logs.PollIntervalMs) * time.Millisecond
+}
@@ -0,0 +1,161 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"aocdpsmetr/internal/source"
+)
+
+// GetSourceKinds возвращает имена источников событий, которые можно передать в StartSources
+func (a *App) GetSourceKinds() []string {
+	return source.Kinds()
+}
+
+// StartSources начинает мониторинг из одного или нескольких источников
+// событий. События всех источников идут в одну сессию. Пустой путь файла
+// означает текущий файл лога; начальная позиция и интервал проверки файла
+// берутся из настроек.
+func (a *App) StartSources(configs []source.Config) string {
+	fmt.Println("StartSources called")
+	a.lifecycle.Lock()
+	defer a.lifecycle.Unlock()
+	if a.monitoring() {
+		fmt.Println("Already monitoring")
+		return "Already monitoring"
+	}
+
+	if err := a.startSources(configs); err != nil {
+		return "Failed to start monitoring: " + err.Error()
+	}
+	return "Monitoring started"
+}
+
+// monitoring сообщает, что источник событий запущен
+func (a *App) monitoring() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.source != nil
+}
+
+// startSources создает источники по настройкам, объединяет их и делает
+// текущими. Вызывается под a.lifecycle, но не под a.mu: Start может долго
+// читать резервные логи, и остальные привязки не должны его ждать.
+func (a *App) startSources(configs []source.Config) error {
+	if len(configs) == 0 {
+		return fmt.Errorf("no event sources")
+	}
+
+	current := a.currentSettings()
+	position, err := startPosition(current.Monitoring)
+	if err != nil {
+		return fmt.Errorf("invalid start mode: %w", err)
+	}
+
+	defaultLog := ""
+	sources := make([]source.EventSource, 0, len(configs))
+	for _, cfg := range configs {
+		if cfg.Path == "" && defaultLog == "" {
+			defaultLog = a.findLogFile()
+		}
+		cfg.DefaultLog = defaultLog
+		cfg.Start = position
+		cfg.PollInterval = pollInterval(current)
+
+		src, err := source.New(cfg)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, src)
+	}
+
+	return a.startSource(source.Merge(sources...))
+}
+
+// startSource запускает источник и чтение его каналов, затем делает его
+// текущим под a.mu. Вызывается под a.lifecycle.
+func (a *App) startSource(src source.EventSource) error {
+	// Источник может отдавать события уже внутри Start, поэтому читаем заранее
+	ctx, cancel := context.WithCancel(context.Background())
+	go a.consume(ctx, src)
+
+	// Бои воспроизводимого лога завершаются по его времени, а не по реальному
+	if clocked, ok := source.Find[source.Clocked](src); ok {
+		a.calculator.SetClock(clocked.Clock)
+	}
+
+	if err := src.Start(); err != nil {
+		src.Stop()
+		cancel()
+		a.calculator.SetClock(time.Now)
+		a.onMonitoringError(err)
+		fmt.Println("Failed to start monitoring:", err)
+		return err
+	}
+	// Настройки могли измениться, пока источник запускался без блокировки
+	a.mu.Lock()
+	a.source = src
+	a.stopSource = cancel
+	setPollInterval(src, pollInterval(a.settings))
+	a.mu.Unlock()
+
+	fmt.Println("Monitoring started successfully")
+	return nil
+}
+
+// stopSources останавливает текущий источник. Статистика остается до
+// сброса. Вызывается под a.lifecycle и a.mu.
+func (a *App) stopSources() {
+	a.source.Stop()
+	a.stopSource()
+	a.source = nil
+	a.stopSource = nil
+	a.calculator.SetClock(time.Now)
+}
+
+// consume читает события и ошибки источника, пока его не остановят
+func (a *App) consume(ctx context.Context, src source.EventSource) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case batch := <-src.Events():
+			a.handleBatch(src, batch)
+		case err := <-src.Errors():
+			a.onMonitoringError(err)
+		}
+	}
+}
+
+// handleBatch применяет пачку источника к калькулятору
+func (a *App) handleBatch(src source.EventSource, batch source.Batch) {
+	// Пачки объединенных источников не говорят, от какого они источника, а
+	// перемотка одного из них не должна стирать события остальных
+	if batch.Reset && !source.IsMerged(src) {
+		a.calculator.ResetSession()
+	}
+	if batch.Rotated != "" {
+		a.onLogRotated(batch.Rotated)
+	}
+	if len(batch.Events) > 0 {
+		a.processEvents(batch.Events)
+	}
+	if batch.Finished {
+		a.onSourceFinished(src)
+	}
+}
+
+// onSourceFinished вызывается, когда у источника закончились данные
+func (a *App) onSourceFinished(src source.EventSource) {
+	// Новых событий не будет, а время воспроизведения в мгновенном режиме
+	// стоит, поэтому бой закрываем сами. Если источников несколько, бой еще
+	// могут продолжить остальные.
+	if !source.IsMerged(src) {
+		a.calculator.StopCombat()
+	}
+
+	if playback, ok := source.Find[source.Playback](src); ok && playback.State().Finished {
+		a.emit(EventReplayFinished, replayState(playback.State()))
+	}
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"text/tabwriter"
+
+	"aocdpsmetr/internal/analysis"
+	"aocdpsmetr/internal/metrics"
+)
+
+// runAnalyze печатает сводку по каждому бою лога
+func runAnalyze(args []string, stdout, stderr, debug io.Writer) int {
+	flags := newFlagSet("analyze", "<log>", stderr)
+	policy := segmentationFlags(flags)
+	top := flags.Int("top", 3, "number of top abilities to list per combat (0 to hide)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	path, ok := logArgument(flags, stderr)
+	if !ok {
+		return 2
+	}
+
+	session, err := analyzeFile(path, policy, debug)
+	if err != nil {
+		fmt.Fprintln(stderr, "analyze:", err)
+		return 1
+	}
+
+	printEncounters(stdout, path, session, *top)
+	return 0
+}
+
+// analyzeFile анализирует лог с политикой из флагов; Ctrl+C прерывает анализ
+func analyzeFile(path string, policy func() (metrics.SegmentationPolicy, error), debug io.Writer) (*metrics.CombatSession, error) {
+	segmentation, err := policy()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	return analysis.AnalyzeFile(ctx, path, analysis.Options{Policy: segmentation, Log: debug})
+}
+
+// printEncounters печатает таблицу боев и итог по сессии
+func printEncounters(w io.Writer, path string, session *metrics.CombatSession, top int) {
+	fmt.Fprintf(w, "Log: %s\n", path)
+	fmt.Fprintf(w, "Encounters: %d, log duration: %s\n\n", len(session.Encounters), formatDuration(session.Elapsed()))
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(table, "#\tStart\tDuration\tDamage\tDPS\tHealing\tHPS\tTaken\tKills\tCrit %\t")
+	for i, combat := range session.Encounters {
+		seconds := combat.Duration.Seconds()
+		fmt.Fprintf(table, "%d\t%s\t%s\t%d\t%.0f\t%d\t%.0f\t%d\t%d\t%.1f\t\n",
+			i+1,
+			combat.StartTime.Local().Format("15:04:05"),
+			formatDuration(combat.Duration),
+			combat.Stats.TotalDamage,
+			perSecond(combat.Stats.TotalDamage, seconds),
+			combat.Stats.TotalHealing,
+			perSecond(combat.Stats.TotalHealing, seconds),
+			combat.DamageTaken.TotalDamage,
+			combat.Stats.TotalKills,
+			percent(combat.Stats.CritHits, combat.Stats.TotalHits),
+		)
+	}
+	fmt.Fprintf(table, "Total\t\t\t%d\t\t%d\t\t%d\t%d\t%.1f\t\n",
+		session.Stats.TotalDamage,
+		session.Stats.TotalHealing,
+		session.DamageTaken.TotalDamage,
+		session.Stats.TotalKills,
+		percent(session.Stats.CritHits, session.Stats.TotalHits),
+	)
+	table.Flush()
+
+	if top <= 0 {
+		return
+	}
+	for i, combat := range session.Encounters {
+		abilities := topAbilities(combat.Abilities, top)
+		if len(abilities) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n#%d top abilities:\n", i+1)
+		for _, ability := range abilities {
+			fmt.Fprintf(w, "  %-32s %10d  %5.1f%%\n",
+				truncate(ability.Name, 32), ability.Damage, percent(ability.Damage, combat.Stats.TotalDamage))
+		}
+	}
+}
+
+// topAbilities возвращает до n способностей с наибольшим уроном
+func topAbilities(stats map[string]*metrics.AbilityStats, n int) []*metrics.AbilityStats {
+	abilities := make([]*metrics.AbilityStats, 0, len(stats))
+	for _, ability := range stats {
+		if ability.Damage > 0 {
+			abilities = append(abilities, ability)
+		}
+	}
+
+	sort.Slice(abilities, func(i, j int) bool {
+		return abilities[i].Damage > abilities[j].Damage
+	})
+	if len(abilities) > n {
+		abilities = abilities[:n]
+	}
+	return abilities
+}
+
+// perSecond делит значение на длительность в секундах
+func perSecond(value int, seconds float64) float64 {
+	if seconds <= 0 {
+		return 0
+	}
+	return float64(value) / seconds
+}
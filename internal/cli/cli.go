@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"aocdpsmetr/internal/metrics"
+)
+
+// usage справка по командам
+const usage = `Usage: aocdpsmetr-cli [-v] <command> [options] <log>
+
+Commands:
+  analyze <log>   print per-combat summaries of a log file
+  tail <log>      follow a log file and show live statistics
+  export <log>    write a combat report as JSON or CSV
+
+Run "aocdpsmetr-cli <command> -h" for command options.
+`
+
+// command консольная команда. В debug пишется отладочный вывод калькулятора и
+// watcher: stderr с флагом -v, иначе он отбрасывается.
+type command func(args []string, stdout, stderr, debug io.Writer) int
+
+// commands команды по имени
+var commands = map[string]command{
+	"analyze": runAnalyze,
+	"tail":    runTail,
+	"export":  runExport,
+}
+
+// Run выполняет консольную команду и возвращает код выхода
+func Run(args []string, stdout, stderr io.Writer) int {
+	global := flag.NewFlagSet("aocdpsmetr-cli", flag.ContinueOnError)
+	global.SetOutput(stderr)
+	global.Usage = func() { fmt.Fprint(stderr, usage) }
+	verbose := global.Bool("v", false, "print debug output of the calculator and log watcher to stderr")
+	if err := global.Parse(args); err != nil {
+		return 2
+	}
+
+	if global.NArg() == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	name := global.Arg(0)
+	run, exists := commands[name]
+	if !exists {
+		fmt.Fprintf(stderr, "unknown command %q\n\n%s", name, usage)
+		return 2
+	}
+	// stdout занят отчетом, поэтому отладку пишем в stderr или отбрасываем
+	debug := io.Discard
+	if *verbose {
+		debug = stderr
+	}
+	return run(global.Args()[1:], stdout, stderr, debug)
+}
+
+// segmentationFlags добавляет общие флаги разбиения лога на бои
+func segmentationFlags(flags *flag.FlagSet) func() (metrics.SegmentationPolicy, error) {
+	mode := flags.String("segment", string(metrics.SegmentIdle), "combat segmentation: idle or kills")
+	idle := flags.Duration("idle", metrics.DefaultIdleTimeout, "idle timeout that ends a combat")
+
+	return func() (metrics.SegmentationPolicy, error) {
+		// В консоли бой нельзя начать или закончить вручную, и в ручном режиме
+		// все события прошли бы мимо боев
+		if metrics.SegmentationMode(*mode) == metrics.SegmentManual {
+			return nil, fmt.Errorf("segmentation %q is not supported in the command line, use idle or kills", *mode)
+		}
+		return metrics.NewSegmentationPolicy(metrics.SegmentationMode(*mode), *idle)
+	}
+}
+
+// logArgument возвращает единственный позиционный аргумент - путь к логу
+func logArgument(flags *flag.FlagSet, stderr io.Writer) (string, bool) {
+	if flags.NArg() != 1 {
+		fmt.Fprintf(stderr, "%s: expected exactly one log file\n", flags.Name())
+		flags.Usage()
+		return "", false
+	}
+	return flags.Arg(0), true
+}
+
+// formatDuration форматирует длительность как 1h02m03s
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	h, m, s := int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60
+	if h > 0 {
+		return fmt.Sprintf("%dh%02dm%02ds", h, m, s)
+	}
+	if m > 0 {
+		return fmt.Sprintf("%dm%02ds", m, s)
+	}
+	return fmt.Sprintf("%ds", s)
+}
+
+// truncate обрезает строку до max символов
+func truncate(value string, max int) string {
+	runes := []rune(value)
+	if len(runes) <= max {
+		return value
+	}
+	return string(runes[:max-1]) + "…"
+}
+
+// percent возвращает долю part от total в процентах
+func percent(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
+}
+
+// newFlagSet создает набор флагов команды
+func newFlagSet(name, args string, stderr io.Writer) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: aocdpsmetr-cli %s [options] %s\n\nOptions:\n", name, args)
+		flags.PrintDefaults()
+	}
+	return flags
+}
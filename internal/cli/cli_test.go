@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fixture лог из двух боев: гоблин убит за 3 с, по орку 2 с урона с исцелением
+const fixture = "testdata/combat.log"
+
+// run выполняет команду и возвращает stdout; stderr при ошибке выводится в тест
+func run(t *testing.T, args ...string) string {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	if code := Run(args, &stdout, &stderr); code != 0 {
+		t.Fatalf("%v: exit code %d, stderr: %s", args, code, stderr.String())
+	}
+	return stdout.String()
+}
+
+func TestAnalyze(t *testing.T) {
+	out := run(t, "analyze", fixture)
+
+	for _, want := range []string{"Encounters: 2, log duration: 1m02s", "Total", "1050", "Slash", "Fireball"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output has no %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestExportJSON(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "report.json")
+	if out := run(t, "export", "-o", output, fixture); out != "" {
+		t.Errorf("stdout = %q, want empty with -o", out)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Version != reportVersion || report.Total.Damage != 1050 || report.Total.Healing != 80 {
+		t.Errorf("total = %+v", report.Total)
+	}
+	if len(report.Encounters) != 2 {
+		t.Fatalf("got %d encounters, want 2", len(report.Encounters))
+	}
+	first := report.Encounters[0]
+	if first.Damage != 450 || first.Duration != 3 || first.Kills != 1 || first.DamageTaken != 50 {
+		t.Errorf("first encounter = %+v", first)
+	}
+	if len(first.Abilities) != 1 || first.Abilities[0].Name != "Slash" || first.Abilities[0].MaxHit != 200 {
+		t.Errorf("first encounter abilities = %+v", first.Abilities)
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	records, err := csv.NewReader(strings.NewReader(run(t, "export", "-format", "csv", fixture))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Заголовок и строка на каждый бой
+	if len(records) != 3 {
+		t.Fatalf("got %d rows, want 3", len(records))
+	}
+	if got := strings.Join(records[1][3:6], ","); got != "3.00,450,150.00" {
+		t.Errorf("first combat duration,damage,dps = %s", got)
+	}
+	if got := strings.Join(records[2][3:6], ","); got != "2.00,600,300.00" {
+		t.Errorf("second combat duration,damage,dps = %s", got)
+	}
+}
+
+func TestExportReportsWriteError(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	output := filepath.Join(t.TempDir(), "missing", "report.json")
+	if code := Run([]string{"export", "-o", output, fixture}, &stdout, &stderr); code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+	if !strings.Contains(stderr.String(), "export:") {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+}
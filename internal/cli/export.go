@@ -0,0 +1,235 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"aocdpsmetr/internal/metrics"
+)
+
+// reportVersion версия формата отчета export
+const reportVersion = 1
+
+// Report отчет по логу
+type Report struct {
+	Version    int               `json:"version"`
+	File       string            `json:"file"`
+	Total      EncounterReport   `json:"total"`
+	Encounters []EncounterReport `json:"encounters"`
+}
+
+// EncounterReport итоги боя или всей сессии
+type EncounterReport struct {
+	ID          string          `json:"id,omitempty"`
+	StartTime   string          `json:"startTime"` // RFC3339
+	EndTime     string          `json:"endTime"`   // RFC3339
+	Duration    float64         `json:"duration"`  // Секунды
+	Damage      int             `json:"damage"`
+	DPS         float64         `json:"dps"`
+	Hits        int             `json:"hits"`
+	Crits       int             `json:"crits"`
+	CritRate    float64         `json:"critRate"`
+	Healing     int             `json:"healing"`
+	HPS         float64         `json:"hps"`
+	DamageTaken int             `json:"damageTaken"`
+	DTPS        float64         `json:"dtps"`
+	Kills       int             `json:"kills"`
+	Abilities   []AbilityReport `json:"abilities"`
+	Targets     []TargetReport  `json:"targets"`
+}
+
+// AbilityReport итоги способности
+type AbilityReport struct {
+	Name     string  `json:"name"`
+	Damage   int     `json:"damage"`
+	Healing  int     `json:"healing"`
+	Hits     int     `json:"hits"`
+	Crits    int     `json:"crits"`
+	CritRate float64 `json:"critRate"`
+	MinHit   int     `json:"minHit"`
+	MaxHit   int     `json:"maxHit"`
+	AvgHit   float64 `json:"avgHit"`
+}
+
+// TargetReport итоги по цели
+type TargetReport struct {
+	Name   string `json:"name"`
+	Damage int    `json:"damage"`
+	Hits   int    `json:"hits"`
+	Kills  int    `json:"kills"`
+}
+
+// runExport записывает отчет по логу в JSON или CSV
+func runExport(args []string, stdout, stderr, debug io.Writer) int {
+	flags := newFlagSet("export", "<log>", stderr)
+	policy := segmentationFlags(flags)
+	format := flags.String("format", "json", "report format: json (full report) or csv (one row per combat)")
+	output := flags.String("o", "", "output file (default stdout)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	path, ok := logArgument(flags, stderr)
+	if !ok {
+		return 2
+	}
+
+	var write func(io.Writer, Report) error
+	switch *format {
+	case "json":
+		write = writeJSON
+	case "csv":
+		write = writeCSV
+	default:
+		fmt.Fprintf(stderr, "export: unknown format %q\n", *format)
+		return 2
+	}
+
+	session, err := analyzeFile(path, policy, debug)
+	if err != nil {
+		fmt.Fprintln(stderr, "export:", err)
+		return 1
+	}
+	report := buildReport(path, session)
+
+	if *output == "" {
+		err = write(stdout, report)
+	} else {
+		err = writeFile(*output, report, write)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "export:", err)
+		return 1
+	}
+	return 0
+}
+
+// writeFile записывает отчет в файл. Ошибка закрытия тоже возвращается:
+// при ней на диск могла попасть только часть отчета.
+func writeFile(path string, report Report, write func(io.Writer, Report) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(file, report); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// buildReport собирает отчет по сессии
+func buildReport(path string, session *metrics.CombatSession) Report {
+	report := Report{
+		Version:    reportVersion,
+		File:       path,
+		Total:      encounterReport("", session.StartTime, session.LastActivity, &session.Breakdown),
+		Encounters: make([]EncounterReport, 0, len(session.Encounters)),
+	}
+	for _, combat := range session.Encounters {
+		report.Encounters = append(report.Encounters,
+			encounterReport(combat.ID, combat.StartTime, combat.EndTime, &combat.Breakdown))
+	}
+	return report
+}
+
+// encounterReport собирает итоги боя или сессии
+func encounterReport(id string, start, end time.Time, b *metrics.Breakdown) EncounterReport {
+	seconds := end.Sub(start).Seconds()
+	report := EncounterReport{
+		ID:          id,
+		StartTime:   start.Format(time.RFC3339),
+		EndTime:     end.Format(time.RFC3339),
+		Duration:    seconds,
+		Damage:      b.Stats.TotalDamage,
+		DPS:         perSecond(b.Stats.TotalDamage, seconds),
+		Hits:        b.Stats.TotalHits,
+		Crits:       b.Stats.CritHits,
+		CritRate:    percent(b.Stats.CritHits, b.Stats.TotalHits),
+		Healing:     b.Stats.TotalHealing,
+		HPS:         perSecond(b.Stats.TotalHealing, seconds),
+		DamageTaken: b.DamageTaken.TotalDamage,
+		DTPS:        perSecond(b.DamageTaken.TotalDamage, seconds),
+		Kills:       b.Stats.TotalKills,
+		Abilities:   make([]AbilityReport, 0, len(b.Abilities)),
+		Targets:     make([]TargetReport, 0, len(b.Targets)),
+	}
+
+	for _, ability := range b.Abilities {
+		report.Abilities = append(report.Abilities, AbilityReport{
+			Name:     ability.Name,
+			Damage:   ability.Damage,
+			Healing:  ability.Healing,
+			Hits:     ability.Hits,
+			Crits:    ability.Crits,
+			CritRate: percent(ability.Crits, ability.Hits),
+			MinHit:   ability.MinHit,
+			MaxHit:   ability.MaxHit,
+			AvgHit:   ability.AverageHit(),
+		})
+	}
+	sort.Slice(report.Abilities, func(i, j int) bool {
+		if report.Abilities[i].Damage != report.Abilities[j].Damage {
+			return report.Abilities[i].Damage > report.Abilities[j].Damage
+		}
+		return report.Abilities[i].Name < report.Abilities[j].Name
+	})
+
+	for _, target := range b.Targets {
+		report.Targets = append(report.Targets, TargetReport{
+			Name:   target.Name,
+			Damage: target.Damage,
+			Hits:   target.Hits,
+			Kills:  target.Kills,
+		})
+	}
+	sort.Slice(report.Targets, func(i, j int) bool {
+		if report.Targets[i].Damage != report.Targets[j].Damage {
+			return report.Targets[i].Damage > report.Targets[j].Damage
+		}
+		return report.Targets[i].Name < report.Targets[j].Name
+	})
+
+	return report
+}
+
+// writeJSON записывает полный отчет
+func writeJSON(w io.Writer, report Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// writeCSV записывает по строке на бой
+func writeCSV(w io.Writer, report Report) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{
+		"id", "startTime", "endTime", "duration", "damage", "dps", "hits", "crits", "critRate",
+		"healing", "hps", "damageTaken", "dtps", "kills",
+	})
+
+	for _, e := range report.Encounters {
+		writer.Write([]string{
+			e.ID, e.StartTime, e.EndTime,
+			formatFloat(e.Duration),
+			strconv.Itoa(e.Damage), formatFloat(e.DPS),
+			strconv.Itoa(e.Hits), strconv.Itoa(e.Crits), formatFloat(e.CritRate),
+			strconv.Itoa(e.Healing), formatFloat(e.HPS),
+			strconv.Itoa(e.DamageTaken), formatFloat(e.DTPS),
+			strconv.Itoa(e.Kills),
+		})
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// formatFloat форматирует число с двумя знаками после запятой
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
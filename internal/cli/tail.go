@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+
+	"aocdpsmetr/internal/metrics"
+	"aocdpsmetr/internal/watcher"
+)
+
+// clearScreen переводит курсор в начало и очищает терминал
+const clearScreen = "\033[H\033[2J"
+
+// runTail следит за логом и перерисовывает таблицу статистики в терминале
+func runTail(args []string, stdout, stderr, debug io.Writer) int {
+	flags := newFlagSet("tail", "<log>", stderr)
+	policy := segmentationFlags(flags)
+	from := flags.String("from", string(watcher.StartFromEnd), "where to start reading: end, beginning, timestamp or session")
+	since := flags.String("since", "", "RFC3339 time for -from timestamp")
+	refresh := flags.Duration("refresh", time.Second, "screen refresh interval")
+	top := flags.Int("top", 10, "number of abilities to show")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	path, ok := logArgument(flags, stderr)
+	if !ok {
+		return 2
+	}
+
+	segmentation, err := policy()
+	if err != nil {
+		fmt.Fprintln(stderr, "tail:", err)
+		return 2
+	}
+	position, err := startPosition(*from, *since)
+	if err != nil {
+		fmt.Fprintln(stderr, "tail:", err)
+		return 2
+	}
+	if *refresh <= 0 {
+		fmt.Fprintln(stderr, "tail: refresh interval must be positive")
+		return 2
+	}
+
+	calculator := metrics.NewCalculator()
+	calculator.SetSegmentationPolicy(segmentation)
+	calculator.SetLogOutput(debug)
+
+	// Последнее событие мониторинга показываем в строке состояния
+	var status atomic.Value
+	status.Store("")
+
+	w := watcher.NewWatcher(path, calculator.ProcessEvents)
+	w.SetStartPosition(position)
+	w.SetLogOutput(debug)
+	w.OnRotate(func(reason string) {
+		status.Store(fmt.Sprintf("%s log %s, reading from the beginning", time.Now().Format("15:04:05"), reason))
+	})
+	w.OnError(func(err error) {
+		status.Store(fmt.Sprintf("%s error: %v", time.Now().Format("15:04:05"), err))
+	})
+	if err := w.Start(); err != nil {
+		w.Stop()
+		fmt.Fprintln(stderr, "tail:", err)
+		return 1
+	}
+	defer w.Stop()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	ticker := time.NewTicker(*refresh)
+	defer ticker.Stop()
+
+	for {
+		calculator.Tick()
+		renderLive(stdout, path, calculator.GetSession(), *top, status.Load().(string))
+
+		select {
+		case <-ctx.Done():
+			return 0
+		case <-ticker.C:
+		}
+	}
+}
+
+// startPosition переводит флаги -from и -since в начальную позицию watcher
+func startPosition(from, since string) (watcher.StartPosition, error) {
+	mode, err := watcher.ParseStartMode(from)
+	if err != nil {
+		return watcher.StartPosition{}, err
+	}
+
+	position := watcher.StartPosition{Mode: mode}
+	if mode == watcher.StartFromTime {
+		if position.Since, err = time.Parse(time.RFC3339, since); err != nil {
+			return watcher.StartPosition{}, fmt.Errorf("invalid -since: %w", err)
+		}
+	}
+	return position, nil
+}
+
+// renderLive перерисовывает экран: общие показатели и способности текущего
+// боя, а если боя нет - всей сессии
+func renderLive(w io.Writer, path string, session *metrics.CombatSession, top int, status string) {
+	fmt.Fprint(w, clearScreen)
+	fmt.Fprintf(w, "AOC DPS Meter - %s (Ctrl+C to exit)\n\n", path)
+
+	abilities, total := session.Abilities, session.Stats.TotalDamage
+	if combat := session.CurrentCombat; combat != nil && combat.IsActive {
+		fmt.Fprintf(w, "Combat: active, %s\n", formatDuration(combat.Elapsed()))
+		abilities, total = combat.Abilities, combat.Stats.TotalDamage
+	} else {
+		fmt.Fprintf(w, "Combat: idle, %d encounters\n", len(session.Encounters))
+	}
+
+	dps, hps := session.DPSStats, session.HPSStats
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(table, "DPS\tcurrent %.0f\tavg %.0f\tmax %.0f\t15s %.0f\t30s %.0f\n",
+		dps.CurrentDPS, dps.AvgDPS, dps.MaxDPS, dps.Window15s, dps.Window30s)
+	fmt.Fprintf(table, "HPS\tcurrent %.0f\tavg %.0f\tmax %.0f\t15s %.0f\t30s %.0f\n",
+		hps.CurrentHPS, hps.AvgHPS, hps.MaxHPS, hps.Window15s, hps.Window30s)
+	fmt.Fprintf(table, "Damage\t%d\thits %d\tcrits %d\tcrit %.1f%%\tkills %d\n",
+		session.Stats.TotalDamage, session.Stats.TotalHits, session.Stats.CritHits,
+		percent(session.Stats.CritHits, session.Stats.TotalHits), session.Stats.TotalKills)
+	fmt.Fprintf(table, "Taken\t%d\tdtps %.0f\n", session.DamageTaken.TotalDamage, session.DamageTaken.CurrentDTPS)
+	table.Flush()
+
+	fmt.Fprintf(w, "\n%-32s %10s %6s %6s %7s %8s\n", "Ability", "Damage", "%", "Hits", "Crit %", "Max hit")
+	for _, ability := range topAbilities(abilities, top) {
+		fmt.Fprintf(w, "%-32s %10d %6.1f %6d %7.1f %8d\n",
+			truncate(ability.Name, 32), ability.Damage, percent(ability.Damage, total),
+			ability.Hits, percent(ability.Crits, ability.Hits), ability.MaxHit)
+	}
+
+	if status != "" {
+		fmt.Fprintf(w, "\n%s\n", status)
+	}
+}
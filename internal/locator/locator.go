@@ -0,0 +1,102 @@
+package locator
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// logFile имя файла лога игры
+const logFile = "AOC.log"
+
+// logsDir путь к каталогу логов внутри AppData/Local пользователя
+var logsDir = filepath.Join("AOC", "Saved", "Logs")
+
+// Источники, в которых найден лог
+const (
+	SourceWindows = "windows"
+	SourceProton  = "proton"
+	SourceLutris  = "lutris"
+	SourceWine    = "wine"
+)
+
+// Candidate найденный файл лога
+type Candidate struct {
+	Path    string
+	Source  string // Где найден: SourceWindows, SourceProton, SourceLutris или SourceWine
+	ModTime time.Time
+}
+
+// Locator ищет файл лога игры в известных местах установки: в профиле
+// Windows, в префиксах Proton всех библиотек Steam и в префиксах Lutris/Wine.
+// Home и Getenv можно подменить, чтобы искать в тестовом дереве каталогов.
+type Locator struct {
+	Home   string
+	Getenv func(key string) string
+}
+
+// New создает Locator для текущего пользователя
+func New() *Locator {
+	home, _ := os.UserHomeDir()
+	return &Locator{
+		Home:   home,
+		Getenv: os.Getenv,
+	}
+}
+
+// Find возвращает все найденные файлы лога, от измененного последним к самому старому
+func (l *Locator) Find() []Candidate {
+	var candidates []Candidate
+	seen := make(map[string]bool)
+
+	add := func(source string, paths ...string) {
+		for _, path := range paths {
+			path = filepath.Clean(path)
+			info, err := os.Stat(path)
+			if err != nil || info.IsDir() {
+				continue
+			}
+
+			// ~/.steam/steam обычно ссылка на ~/.local/share/Steam; один файл не повторяем
+			key := path
+			if resolved, err := filepath.EvalSymlinks(path); err == nil {
+				key = resolved
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			candidates = append(candidates, Candidate{Path: path, Source: source, ModTime: info.ModTime()})
+		}
+	}
+
+	add(SourceWindows, l.windowsPaths()...)
+	add(SourceProton, l.protonPaths()...)
+	add(SourceLutris, l.lutrisPaths()...)
+	add(SourceWine, l.winePaths()...)
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].ModTime.After(candidates[j].ModTime)
+	})
+	return candidates
+}
+
+// windowsPaths возвращает стандартные пути лога в профиле Windows
+func (l *Locator) windowsPaths() []string {
+	var paths []string
+	if local := l.Getenv("LOCALAPPDATA"); local != "" {
+		paths = append(paths, filepath.Join(local, logsDir, logFile))
+	}
+	if profile := l.Getenv("USERPROFILE"); profile != "" {
+		paths = append(paths, filepath.Join(profile, "AppData", "Local", logsDir, logFile))
+	}
+	return paths
+}
+
+// prefixLogs возвращает файлы лога всех пользователей Wine-префикса
+func prefixLogs(prefix string) []string {
+	pattern := filepath.Join(prefix, "drive_c", "users", "*", "AppData", "Local", logsDir, logFile)
+	matches, _ := filepath.Glob(pattern)
+	return matches
+}
@@ -0,0 +1,130 @@
+package locator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// writeLog создает лог в Wine-префиксе с заданным временем изменения
+func writeLog(t *testing.T, prefix string, modTime time.Time) string {
+	t.Helper()
+	dir := filepath.Join(prefix, "drive_c", "users", "steamuser", "AppData", "Local", logsDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, logFile)
+	if err := os.WriteFile(path, []byte("log\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// writeFile создает файл вместе с каталогами
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	// Обычная установка Steam и ссылка на нее ~/.steam/steam
+	native := filepath.Join(home, ".local", "share", "Steam")
+	nativeLog := writeLog(t, filepath.Join(native, "steamapps", "compatdata", "2000", "pfx"), base)
+	if err := os.MkdirAll(filepath.Join(home, ".steam"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(native, filepath.Join(home, ".steam", "steam")); err != nil {
+		t.Skip("symlinks are not supported:", err)
+	}
+
+	// Flatpak Steam с дополнительной библиотекой
+	library := filepath.Join(root, "library")
+	libraryLog := writeLog(t, filepath.Join(library, "steamapps", "compatdata", "3000", "pfx"), base.Add(time.Hour))
+	flatpak := filepath.Join(home, ".var", "app", "com.valvesoftware.Steam", ".local", "share", "Steam")
+	writeFile(t, filepath.Join(flatpak, "steamapps", "libraryfolders.vdf"), `"libraryfolders"
+{
+	"0"
+	{
+		"path"		"`+library+`"
+	}
+	"1"
+	{
+		"path"		"D:\\Games\\SteamLibrary"
+	}
+}
+`)
+
+	// Lutris с префиксом вне ~/Games
+	lutrisPrefix := filepath.Join(root, "lutris")
+	lutrisLog := writeLog(t, lutrisPrefix, base.Add(3*time.Hour))
+	writeFile(t, filepath.Join(home, ".config", "lutris", "games", "ashes-of-creation.yml"),
+		"game:\n  exe: AOC.exe\n  prefix: \""+lutrisPrefix+"\"\nwine:\n  version: lutris-ge\n")
+
+	// Префикс из WINEPREFIX
+	winePrefix := filepath.Join(root, "wine")
+	wineLog := writeLog(t, winePrefix, base.Add(2*time.Hour))
+
+	env := map[string]string{"WINEPREFIX": winePrefix}
+	l := &Locator{Home: home, Getenv: func(key string) string { return env[key] }}
+
+	var got []string
+	var sources []string
+	for _, candidate := range l.Find() {
+		got = append(got, candidate.Path)
+		sources = append(sources, candidate.Source)
+	}
+
+	// Лог обычной установки найден через оба корня Steam, но указан один раз
+	nativeViaLink := filepath.Join(home, ".steam", "steam", "steamapps", "compatdata", "2000", "pfx",
+		"drive_c", "users", "steamuser", "AppData", "Local", logsDir, logFile)
+	want := []string{lutrisLog, wineLog, libraryLog, nativeViaLink}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Find() =\n%v\nwant\n%v", got, want)
+	}
+	if wantSources := []string{SourceLutris, SourceWine, SourceProton, SourceProton}; !reflect.DeepEqual(sources, wantSources) {
+		t.Errorf("sources = %v, want %v", sources, wantSources)
+	}
+	if resolved, _ := filepath.EvalSymlinks(got[3]); resolved != mustEval(t, nativeLog) {
+		t.Errorf("%s resolves to %s, want %s", got[3], resolved, nativeLog)
+	}
+}
+
+// mustEval раскрывает ссылки в пути
+func mustEval(t *testing.T, path string) string {
+	t.Helper()
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resolved
+}
+
+func TestReadLibraryFoldersUnescapesWindowsPaths(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "libraryfolders.vdf")
+	writeFile(t, path, `"libraryfolders"
+{
+	"0"		{ "path"		"C:\\Program Files (x86)\\Steam" }
+	"1"		{ "path"		"D:\\Games\\SteamLibrary" }
+}
+`)
+
+	got := readLibraryFolders(path)
+	want := []string{`C:\Program Files (x86)\Steam`, `D:\Games\SteamLibrary`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readLibraryFolders() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,110 @@
+package locator
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// steamRoots каталоги установки Steam относительно домашнего каталога:
+// обычная установка, Flatpak и Snap
+var steamRoots = []string{
+	filepath.Join(".steam", "steam"),
+	filepath.Join(".local", "share", "Steam"),
+	filepath.Join(".var", "app", "com.valvesoftware.Steam", ".local", "share", "Steam"),
+	filepath.Join("snap", "steam", "common", ".local", "share", "Steam"),
+}
+
+// vdfPathRegex находит пути библиотек в libraryfolders.vdf
+var vdfPathRegex = regexp.MustCompile(`"path"\s+"((?:[^"\\]|\\.)*)"`)
+
+// protonPaths возвращает файлы лога в префиксах Proton всех библиотек Steam.
+// AppID не проверяется: игра может быть добавлена в Steam как сторонняя,
+// и тогда ее префикс получает произвольный номер.
+func (l *Locator) protonPaths() []string {
+	var paths []string
+	for _, library := range l.steamLibraries() {
+		prefixes, _ := filepath.Glob(filepath.Join(library, "steamapps", "compatdata", "*", "pfx"))
+		for _, prefix := range prefixes {
+			paths = append(paths, prefixLogs(prefix)...)
+		}
+	}
+	return paths
+}
+
+// steamLibraries возвращает каталоги установки Steam и библиотеки из их libraryfolders.vdf
+func (l *Locator) steamLibraries() []string {
+	var libraries []string
+	for _, root := range steamRoots {
+		root = filepath.Join(l.Home, root)
+		if _, err := os.Stat(root); err != nil {
+			continue
+		}
+		libraries = append(libraries, root)
+		libraries = append(libraries, readLibraryFolders(filepath.Join(root, "steamapps", "libraryfolders.vdf"))...)
+	}
+	return libraries
+}
+
+// readLibraryFolders читает пути библиотек из libraryfolders.vdf
+func readLibraryFolders(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var libraries []string
+	for _, match := range vdfPathRegex.FindAllStringSubmatch(string(data), -1) {
+		// В VDF обратная косая черта экранируется
+		libraries = append(libraries, strings.ReplaceAll(match[1], `\\`, `\`))
+	}
+	return libraries
+}
+
+// lutrisPaths возвращает файлы лога в префиксах игр Lutris: из конфигураций
+// игр и из каталога ~/Games, куда Lutris ставит игры по умолчанию
+func (l *Locator) lutrisPaths() []string {
+	var paths []string
+
+	configs, _ := filepath.Glob(filepath.Join(l.Home, ".config", "lutris", "games", "*.yml"))
+	for _, config := range configs {
+		if prefix := readLutrisPrefix(config); prefix != "" {
+			paths = append(paths, prefixLogs(prefix)...)
+		}
+	}
+
+	prefixes, _ := filepath.Glob(filepath.Join(l.Home, "Games", "*"))
+	for _, prefix := range prefixes {
+		paths = append(paths, prefixLogs(prefix)...)
+	}
+	return paths
+}
+
+// readLutrisPrefix возвращает значение "prefix:" из конфигурации игры Lutris
+func readLutrisPrefix(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if value, found := strings.CutPrefix(line, "prefix:"); found {
+			return strings.Trim(strings.TrimSpace(value), `"'`)
+		}
+	}
+	return ""
+}
+
+// winePaths возвращает файлы лога в префиксе из WINEPREFIX и в префиксе Wine по умолчанию
+func (l *Locator) winePaths() []string {
+	var paths []string
+	if prefix := l.Getenv("WINEPREFIX"); prefix != "" {
+		paths = append(paths, prefixLogs(prefix)...)
+	}
+	return append(paths, prefixLogs(filepath.Join(l.Home, ".wine"))...)
+}
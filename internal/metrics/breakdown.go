@@ -0,0 +1,202 @@
+package metrics
+
+import "aocdpsmetr/internal/parser"
+
+// newBreakdown создает пустую статистику
+func newBreakdown() Breakdown {
+	return Breakdown{
+		Abilities:      make(map[string]*AbilityStats),
+		Targets:        make(map[string]*TargetStats),
+		TakenBySource:  make(map[string]*SourceStats),
+		TakenByAbility: make(map[string]*AbilityStats),
+	}
+}
+
+// isEmpty сообщает, что в статистику не попало ни одного события боя
+func (b *Breakdown) isEmpty() bool {
+	return b.Stats.TotalHits == 0 && b.Stats.TotalHealingHits == 0 &&
+		b.Stats.TotalKills == 0 && b.DamageTaken.TotalHits == 0
+}
+
+// addDamage учитывает нанесенный урон
+func (b *Breakdown) addDamage(event *parser.DamageEvent) {
+	// Обновляем общую статистику
+	b.Stats.TotalDamage += event.Amount
+	b.Stats.TotalHits++
+	if event.IsCrit {
+		b.Stats.CritHits++
+	}
+
+	// Обновляем статистику по способностям
+	if ability, exists := b.Abilities[event.Ability]; exists {
+		ability.Damage += event.Amount
+		ability.Hits++
+		if event.IsCrit {
+			ability.Crits++
+		}
+		ability.LastUsed = event.Timestamp
+	} else {
+		b.Abilities[event.Ability] = &AbilityStats{
+			Name:     event.Ability,
+			Damage:   event.Amount,
+			Hits:     1,
+			Crits:    boolToInt(event.IsCrit),
+			LastUsed: event.Timestamp,
+		}
+	}
+
+	b.Abilities[event.Ability].recordHit(event.Amount, event.IsCrit)
+	b.changed.abilities.add(event.Ability)
+
+	// Обновляем статистику по целям
+	if target, exists := b.Targets[event.Target]; exists {
+		target.Damage += event.Amount
+		target.Hits++
+		if event.IsCrit {
+			target.Crits++
+		}
+		target.LastHit = event.Timestamp
+	} else {
+		b.Targets[event.Target] = &TargetStats{
+			Name:     event.Target,
+			Damage:   event.Amount,
+			Hits:     1,
+			Crits:    boolToInt(event.IsCrit),
+			FirstHit: event.Timestamp,
+			LastHit:  event.Timestamp,
+		}
+	}
+	b.changed.targets.add(event.Target)
+}
+
+// addDamageTaken учитывает урон, полученный игроком
+func (b *Breakdown) addDamageTaken(event *parser.DamageEvent) {
+	// Обновляем общую статистику
+	b.DamageTaken.TotalDamage += event.Amount
+	b.DamageTaken.TotalHits++
+	if event.IsCrit {
+		b.DamageTaken.CritHits++
+	}
+
+	// Обновляем статистику по источникам
+	if source, exists := b.TakenBySource[event.Source]; exists {
+		source.Damage += event.Amount
+		source.Hits++
+		if event.IsCrit {
+			source.Crits++
+		}
+		source.LastHit = event.Timestamp
+	} else {
+		b.TakenBySource[event.Source] = &SourceStats{
+			Name:    event.Source,
+			Damage:  event.Amount,
+			Hits:    1,
+			Crits:   boolToInt(event.IsCrit),
+			LastHit: event.Timestamp,
+		}
+	}
+	b.changed.takenBySource.add(event.Source)
+
+	// Обновляем статистику по способностям противников
+	if ability, exists := b.TakenByAbility[event.Ability]; exists {
+		ability.Damage += event.Amount
+		ability.Hits++
+		if event.IsCrit {
+			ability.Crits++
+		}
+		ability.LastUsed = event.Timestamp
+	} else {
+		b.TakenByAbility[event.Ability] = &AbilityStats{
+			Name:     event.Ability,
+			Damage:   event.Amount,
+			Hits:     1,
+			Crits:    boolToInt(event.IsCrit),
+			LastUsed: event.Timestamp,
+		}
+	}
+
+	b.TakenByAbility[event.Ability].recordHit(event.Amount, event.IsCrit)
+	b.changed.takenByAbility.add(event.Ability)
+}
+
+// addHeal учитывает исцеление
+func (b *Breakdown) addHeal(event *parser.HealEvent) {
+	// Обновляем общую статистику
+	b.Stats.TotalHealing += event.Amount
+	b.Stats.TotalHealingHits++
+	if event.IsCrit {
+		b.Stats.CritHealing++
+	}
+
+	// Обновляем статистику по способностям
+	if ability, exists := b.Abilities[event.Ability]; exists {
+		ability.Healing += event.Amount
+		ability.HealingHits++
+		if event.IsCrit {
+			ability.CritHealing++
+		}
+		ability.LastUsed = event.Timestamp
+	} else {
+		b.Abilities[event.Ability] = &AbilityStats{
+			Name:        event.Ability,
+			Healing:     event.Amount,
+			HealingHits: 1,
+			CritHealing: boolToInt(event.IsCrit),
+			LastUsed:    event.Timestamp,
+		}
+	}
+	b.changed.abilities.add(event.Ability)
+
+	// Обновляем статистику по целям
+	if target, exists := b.Targets[event.Target]; exists {
+		target.Healing += event.Amount
+		target.HealingHits++
+		if event.IsCrit {
+			target.CritHealing++
+		}
+		target.LastHit = event.Timestamp
+	} else {
+		b.Targets[event.Target] = &TargetStats{
+			Name:        event.Target,
+			Healing:     event.Amount,
+			HealingHits: 1,
+			CritHealing: boolToInt(event.IsCrit),
+			FirstHit:    event.Timestamp,
+			LastHit:     event.Timestamp,
+		}
+	}
+	b.changed.targets.add(event.Target)
+}
+
+// addKill учитывает убийство
+func (b *Breakdown) addKill(event *parser.KillEvent) {
+	// Обновляем общую статистику
+	b.Stats.TotalKills++
+
+	// Обновляем статистику по способностям
+	if ability, exists := b.Abilities[event.Ability]; exists {
+		ability.Kills++
+		ability.LastUsed = event.Timestamp
+	} else {
+		b.Abilities[event.Ability] = &AbilityStats{
+			Name:     event.Ability,
+			Kills:    1,
+			LastUsed: event.Timestamp,
+		}
+	}
+	b.changed.abilities.add(event.Ability)
+
+	// Обновляем статистику по целям
+	if target, exists := b.Targets[event.Target]; exists {
+		target.Kills++
+		target.LastHit = event.Timestamp
+	} else {
+		b.Targets[event.Target] = &TargetStats{
+			Name:     event.Target,
+			Kills:    1,
+			FirstHit: event.Timestamp,
+			LastHit:  event.Timestamp,
+		}
+	}
+	b.changed.targets.add(event.Target)
+}
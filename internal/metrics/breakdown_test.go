@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"aocdpsmetr/internal/parser"
+)
+
+func TestDamageTakenIsSeparate(t *testing.T) {
+	c := NewCalculator()
+	c.SetClock(func() time.Time { return testStart })
+
+	taken := func(offset time.Duration, amount int, source, ability string) *parser.DamageEvent {
+		return &parser.DamageEvent{
+			Timestamp: testStart.Add(offset),
+			Amount:    amount,
+			IsCrit:    source == "Orc",
+			Target:    "You",
+			Source:    source,
+			Ability:   ability,
+		}
+	}
+
+	c.ProcessEvents([]parser.Event{
+		hit(0, 100),
+		taken(time.Second, 50, "Orc", "Axe Strike"),
+		hit(2*time.Second, 100),
+		taken(3*time.Second, 30, "Goblin", "Stab"),
+		taken(4*time.Second, 70, "Orc", "Axe Strike"),
+	})
+	session := c.GetSession()
+
+	// Атаки противников не попадают в нанесенный урон
+	if session.Stats.TotalDamage != 200 || session.Stats.TotalHits != 2 {
+		t.Errorf("dealt = %d in %d hits, want 200 in 2", session.Stats.TotalDamage, session.Stats.TotalHits)
+	}
+	if _, ok := session.Abilities["Axe Strike"]; ok {
+		t.Error("enemy ability in the dealt abilities")
+	}
+	if _, ok := session.Targets["You"]; ok {
+		t.Error("player in the dealt targets")
+	}
+
+	if got := session.DamageTaken; got.TotalDamage != 150 || got.TotalHits != 3 || got.CritHits != 2 {
+		t.Errorf("DamageTaken = %+v, want 150 in 3 hits, 2 crits", got)
+	}
+	if orc := session.TakenBySource["Orc"]; orc == nil || orc.Damage != 120 || orc.Hits != 2 {
+		t.Errorf("TakenBySource[Orc] = %+v, want 120 in 2 hits", orc)
+	}
+	if axe := session.TakenByAbility["Axe Strike"]; axe == nil || axe.Damage != 120 || axe.Hits != 2 {
+		t.Errorf("TakenByAbility[Axe Strike] = %+v, want 120 in 2 hits", axe)
+	}
+
+	// Бой считает полученный урон отдельно, DTPS - за время боя
+	combat := session.CurrentCombat
+	if combat.DamageTaken.TotalDamage != 150 || combat.TakenBySource["Goblin"].Damage != 30 {
+		t.Errorf("combat DamageTaken = %+v", combat.DamageTaken)
+	}
+	if got := session.DamageTaken.AvgDTPS; got != 37.5 {
+		t.Errorf("AvgDTPS = %v, want 37.5", got)
+	}
+}
@@ -0,0 +1,204 @@
+package metrics
+
+import (
+	"time"
+
+	"aocdpsmetr/internal/parser"
+)
+
+// processBuffEvent обрабатывает событие баффа/дебаффа
+func (c *Calculator) processBuffEvent(event *parser.BuffEvent) {
+	key := BuffKey{Name: event.BuffName, Target: event.Target}
+	combat := c.activeCombat()
+
+	switch event.Type {
+	case "Received", "Applied":
+		if _, active := c.session.ActiveBuffs[key]; active {
+			// Повторное наложение активного баффа - это обновление, а не новое применение
+			if combat != nil {
+				combat.buffStats(key).Refreshes++
+			}
+			return
+		}
+
+		c.session.ActiveBuffs[key] = event.Timestamp
+		if combat != nil {
+			stats := combat.buffStats(key)
+			stats.Applications++
+			stats.open(event.Timestamp)
+		}
+	case "Removed":
+		c.removeBuff(key, event.Timestamp)
+	}
+}
+
+// removeBuff снимает бафф и учитывает длительность его применения
+func (c *Calculator) removeBuff(key BuffKey, at time.Time) {
+	since, active := c.session.ActiveBuffs[key]
+	if !active {
+		return
+	}
+	delete(c.session.ActiveBuffs, key)
+
+	if combat := c.activeCombat(); combat != nil {
+		stats := combat.buffStats(key)
+		stats.close(at)
+		stats.CompletedApplications++
+		stats.AppliedDuration += at.Sub(since)
+	}
+}
+
+// removeTargetDebuffs снимает все дебаффы с убитой цели
+func (c *Calculator) removeTargetDebuffs(target string, at time.Time) {
+	for key := range c.session.ActiveBuffs {
+		if key.Target == target {
+			c.removeBuff(key, at)
+		}
+	}
+}
+
+// buffStats возвращает статистику баффа в бою, создавая ее при необходимости
+func (c *Combat) buffStats(key BuffKey) *BuffStats {
+	stats, exists := c.Buffs[key]
+	if !exists {
+		stats = &BuffStats{
+			Name:     key.Name,
+			Target:   key.Target,
+			IsDebuff: key.Target != "You",
+		}
+		c.Buffs[key] = stats
+	}
+	return stats
+}
+
+// openBuffs продолжает в новом бою баффы, наложенные до его начала
+func (c *Combat) openBuffs(active map[BuffKey]time.Time, at time.Time) {
+	for key := range active {
+		c.buffStats(key).open(at)
+	}
+}
+
+// closeBuffs закрывает интервалы активных баффов при завершении боя
+func (c *Combat) closeBuffs(at time.Time) {
+	for _, stats := range c.Buffs {
+		stats.close(at)
+	}
+}
+
+// attributeDamage учитывает урон, нанесенный под действием баффов: баффы на
+// игроке влияют на весь урон, дебаффы - только на урон по своей цели
+func (c *Combat) attributeDamage(event *parser.DamageEvent) {
+	for key, stats := range c.Buffs {
+		if !stats.isActive() {
+			continue
+		}
+		if stats.IsDebuff && key.Target != event.Target {
+			continue
+		}
+
+		stats.DamageDuring += event.Amount
+		if stats.AbilityDamage == nil {
+			stats.AbilityDamage = make(map[string]int)
+		}
+		stats.AbilityDamage[event.Ability] += event.Amount
+	}
+}
+
+// BuffAttribution сравнивает урон в окнах действия баффа и вне их. Дебафф
+// сравнивается только за время боя с его целью: от первого попадания по ней
+// до последнего или до убийства. Иначе время, когда цель еще не была в бою
+// или уже умерла, занижало бы DPS без дебаффа.
+func (c *Combat) BuffAttribution(stats *BuffStats) BuffAttribution {
+	from := c.StartTime
+	until := from.Add(c.Elapsed())
+
+	// База для сравнения: весь урон за бой или урон по цели дебаффа
+	baseline := c.Stats.TotalDamage
+	if stats.IsDebuff {
+		baseline = 0
+		if target, exists := c.Targets[stats.Target]; exists {
+			baseline = target.Damage
+			from, until = target.FirstHit, target.LastHit
+		}
+	}
+
+	active := stats.activeBetween(from, until)
+	result := BuffAttribution{
+		ActiveTime:     active,
+		InactiveTime:   until.Sub(from) - active,
+		DamageActive:   stats.DamageDuring,
+		DamageInactive: baseline - stats.DamageDuring,
+	}
+	result.DPSActive = perSecond(result.DamageActive, result.ActiveTime)
+	result.DPSInactive = perSecond(result.DamageInactive, result.InactiveTime)
+	if result.DPSInactive > 0 && result.ActiveTime > 0 {
+		result.Gain = (result.DPSActive/result.DPSInactive - 1) * 100
+	}
+
+	return result
+}
+
+// BuffUptime возвращает долю времени боя (в процентах), когда бафф был активен
+func (c *Combat) BuffUptime(stats *BuffStats) float64 {
+	duration := c.Elapsed()
+	if duration <= 0 {
+		return 0
+	}
+
+	end := c.StartTime.Add(duration)
+	return float64(stats.ActiveTime(end)) / float64(duration) * 100
+}
+
+// open начинает интервал действия баффа
+func (b *BuffStats) open(at time.Time) {
+	if b.isActive() {
+		return
+	}
+	b.Intervals = append(b.Intervals, BuffInterval{Start: at})
+}
+
+// close завершает открытый интервал действия баффа
+func (b *BuffStats) close(at time.Time) {
+	if b.isActive() {
+		b.Intervals[len(b.Intervals)-1].End = at
+	}
+}
+
+// isActive сообщает, что бафф сейчас действует
+func (b *BuffStats) isActive() bool {
+	n := len(b.Intervals)
+	return n > 0 && b.Intervals[n-1].End.IsZero()
+}
+
+// ActiveTime возвращает суммарное время действия баффа; открытый интервал
+// считается до момента until
+func (b *BuffStats) ActiveTime(until time.Time) time.Duration {
+	return b.activeBetween(time.Time{}, until)
+}
+
+// activeBetween возвращает время действия баффа внутри отрезка [from, until];
+// открытый интервал считается до until
+func (b *BuffStats) activeBetween(from, until time.Time) time.Duration {
+	var total time.Duration
+	for _, interval := range b.Intervals {
+		start, end := interval.Start, interval.End
+		if start.Before(from) {
+			start = from
+		}
+		if end.IsZero() || end.After(until) {
+			end = until
+		}
+		if end.After(start) {
+			total += end.Sub(start)
+		}
+	}
+	return total
+}
+
+// AverageDuration возвращает среднюю длительность завершенного применения
+func (b *BuffStats) AverageDuration() time.Duration {
+	if b.CompletedApplications == 0 {
+		return 0
+	}
+	return b.AppliedDuration / time.Duration(b.CompletedApplications)
+}
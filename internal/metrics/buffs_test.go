@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"aocdpsmetr/internal/parser"
+)
+
+func TestDebuffAttributionUsesTargetEngagement(t *testing.T) {
+	c := NewCalculator()
+	c.SetClock(func() time.Time { return testStart })
+
+	debuff := func(offset time.Duration, kind string) *parser.BuffEvent {
+		return &parser.BuffEvent{
+			Timestamp: testStart.Add(offset),
+			Type:      kind,
+			BuffName:  "Sunder",
+			Target:    "Orc",
+			Source:    "Player",
+		}
+	}
+
+	// Гоблин в бою все 20 секунд, орк - только с 10-й секунды.
+	// Дебафф висит на орке с 10-й по 15-ю секунду.
+	for i := 0; i < 20; i++ {
+		at := time.Duration(i) * time.Second
+		c.ProcessEvent(hit(at, 100))
+
+		switch i {
+		case 10:
+			c.ProcessEvent(debuff(at, "Applied"))
+		case 15:
+			c.ProcessEvent(debuff(at, "Removed"))
+		}
+		if i >= 10 {
+			amount := 100
+			if i < 15 {
+				amount = 200
+			}
+			orc := hit(at, amount)
+			orc.Target = "Orc"
+			c.ProcessEvent(orc)
+		}
+	}
+
+	combat := c.GetSession().CurrentCombat
+	stats := combat.Buffs[BuffKey{Name: "Sunder", Target: "Orc"}]
+	if stats == nil {
+		t.Fatal("debuff stats not recorded")
+	}
+	got := combat.BuffAttribution(stats)
+
+	// Орк в бою с 10-й по 19-ю секунду: 5 секунд под дебаффом, 4 без него
+	if got.ActiveTime != 5*time.Second || got.InactiveTime != 4*time.Second {
+		t.Errorf("active/inactive = %v/%v, want 5s/4s", got.ActiveTime, got.InactiveTime)
+	}
+	if got.DPSActive != 200 || got.DPSInactive != 125 {
+		t.Errorf("DPS active/inactive = %v/%v, want 200/125", got.DPSActive, got.DPSInactive)
+	}
+	if math.Abs(got.Gain-60) > 1e-9 {
+		t.Errorf("Gain = %v, want 60", got.Gain)
+	}
+}
+
+func TestBuffUptime(t *testing.T) {
+	c := NewCalculator()
+	now := testStart
+	c.SetClock(func() time.Time { return now })
+
+	buff := func(offset time.Duration, kind, name, target string) *parser.BuffEvent {
+		return &parser.BuffEvent{Timestamp: testStart.Add(offset), Type: kind, BuffName: name, Target: target}
+	}
+
+	// Бой 0..20 с. Бафф на игроке: 2..6 с с обновлением на 4-й и с 12-й до конца боя.
+	// Дебафф на гоблине с 5-й секунды снимается убийством на 15-й.
+	for i := 0; i <= 20; i++ {
+		at := time.Duration(i) * time.Second
+		switch i {
+		case 2, 4, 12:
+			c.ProcessEvent(buff(at, "Received", "Divine Power", "You"))
+		case 6:
+			c.ProcessEvent(buff(at, "Removed", "Divine Power", "You"))
+		case 5:
+			c.ProcessEvent(buff(at, "Applied", "Volatile", "Goblin"))
+		case 15:
+			c.ProcessEvent(&parser.KillEvent{Timestamp: testStart.Add(at), Target: "Goblin", Ability: "Slash"})
+		}
+		c.ProcessEvent(hit(at, 100))
+	}
+	now = testStart.Add(20 * time.Second)
+	c.StopCombat()
+
+	combat := c.GetSession().CurrentCombat
+	self := combat.Buffs[BuffKey{Name: "Divine Power", Target: "You"}]
+	debuff := combat.Buffs[BuffKey{Name: "Volatile", Target: "Goblin"}]
+	if self == nil || debuff == nil {
+		t.Fatalf("buffs = %v", combat.Buffs)
+	}
+
+	if self.IsDebuff || self.Applications != 2 || self.Refreshes != 1 || self.CompletedApplications != 1 {
+		t.Errorf("self buff = %+v", self)
+	}
+	if got := self.AverageDuration(); got != 4*time.Second {
+		t.Errorf("self buff AverageDuration = %v, want 4s", got)
+	}
+	if got := combat.BuffUptime(self); math.Abs(got-60) > 1e-9 {
+		t.Errorf("self buff uptime = %v, want 60", got)
+	}
+
+	if !debuff.IsDebuff || debuff.Applications != 1 || debuff.AverageDuration() != 10*time.Second {
+		t.Errorf("debuff = %+v", debuff)
+	}
+	if got := combat.BuffUptime(debuff); math.Abs(got-50) > 1e-9 {
+		t.Errorf("debuff uptime = %v, want 50", got)
+	}
+
+	// Бафф, оставшийся на игроке, продолжается в следующем бою с его начала
+	c.ProcessEvent(hit(time.Minute, 100))
+	next := c.GetSession().CurrentCombat
+	stats := next.Buffs[BuffKey{Name: "Divine Power", Target: "You"}]
+	if stats == nil || len(stats.Intervals) != 1 || !stats.Intervals[0].Start.Equal(next.StartTime) {
+		t.Errorf("next combat buff = %+v", stats)
+	}
+}
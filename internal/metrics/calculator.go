@@ -2,395 +2,523 @@ package metrics
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"aocdpsmetr/internal/parser"
 )
 
-// Calculator рассчитывает метрики боя
+// Calculator рассчитывает метрики боя. Все расчеты ведутся по времени из
+// лога, поэтому повторная обработка старого лога дает те же бои и DPS, что и
+// в реальном времени. Часы используются только там, где нужно "сейчас":
+// для завершения боя, после которого новых событий не пришло.
+//
+// Калькулятор безопасен для использования из нескольких горутин. Изменения
+// выполняются по одному под мьютексом, а читатели получают неизменяемые
+// снапшоты сессии, опубликованные после каждого изменения.
 type Calculator struct {
-	session *CombatSession
+	mu       sync.Mutex
+	session  *CombatSession
+	clock    func() time.Time
+	policy   SegmentationPolicy
+	log      io.Writer // Куда писать отладочный вывод
+	snapshot atomic.Pointer[CombatSession]
+
+	onChange      func()
+	onCombatStart func(combat *Combat)
+	onCombatEnd   func(combat *Combat)
+	pending       []func() // Уведомления, отложенные до снятия блокировки
 }
 
 // NewCalculator создает новый калькулятор
 func NewCalculator() *Calculator {
-	return &Calculator{
-		session: &CombatSession{
-			ID:        generateSessionID(),
-			StartTime: time.Now(),
-			IsActive:  true,
-			Abilities: make(map[string]*AbilityStats),
-			Targets:   make(map[string]*TargetStats),
-		},
+	c := &Calculator{
+		clock:  time.Now,
+		policy: IdlePolicy{Timeout: DefaultIdleTimeout},
+		log:    os.Stdout,
 	}
+	c.startNewSession()
+	c.publish()
+	return c
 }
 
-// ProcessEvent обрабатывает событие боя
-func (c *Calculator) ProcessEvent(event interface{}) {
-	now := time.Now()
+// SetClock заменяет источник текущего времени (например, для воспроизведения лога)
+func (c *Calculator) SetClock(clock func() time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.clock = clock
+}
 
-	// Проверяем, нужно ли начать новый бой
-	c.checkCombatStatus(now)
-
-	switch e := event.(type) {
-	case *parser.DamageEvent:
-		fmt.Printf("Processing DamageEvent: %+v\n", e)
-		c.processDamageEvent(e)
-	case *parser.HealEvent:
-		fmt.Printf("Processing HealEvent: %+v\n", e)
-		c.processHealEvent(e)
-	case *parser.KillEvent:
-		fmt.Printf("Processing KillEvent: %+v\n", e)
-		c.processKillEvent(e)
-	case *parser.BuffEvent:
-		fmt.Printf("Processing BuffEvent: %+v\n", e)
-		c.processBuffEvent(e)
-	default:
-		fmt.Printf("Unknown event type: %T\n", event)
-	}
+// SetLogOutput задает, куда писать отладочный вывод (по умолчанию os.Stdout).
+// io.Discard отключает его.
+func (c *Calculator) SetLogOutput(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.log = w
+}
 
-	// Обновляем время последней активности
-	c.session.LastActivity = now
+// logf пишет строку отладочного вывода
+func (c *Calculator) logf(format string, args ...any) {
+	fmt.Fprintf(c.log, format, args...)
+}
 
-	// Добавляем событие в список недавних событий
-	c.addRecentEvent(event)
+// OnChange задает обработчик, который вызывается после публикации нового снапшота
+func (c *Calculator) OnChange(fn func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onChange = fn
 }
 
-// processDamageEvent обрабатывает событие урона
-func (c *Calculator) processDamageEvent(event *parser.DamageEvent) {
+// OnCombatStarted задает обработчик начала боя. Получает копию боя на момент начала.
+func (c *Calculator) OnCombatStarted(fn func(combat *Combat)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onCombatStart = fn
+}
+
+// OnCombatEnded задает обработчик завершения боя. Завершенный бой больше не меняется.
+func (c *Calculator) OnCombatEnded(fn func(combat *Combat)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onCombatEnd = fn
+}
+
+// SetSegmentationPolicy заменяет правило разбиения лога на бои
+func (c *Calculator) SetSegmentationPolicy(policy SegmentationPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.policy = policy
+}
+
+// GetSegmentationPolicy возвращает текущее правило разбиения лога на бои
+func (c *Calculator) GetSegmentationPolicy() SegmentationPolicy {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.policy
+}
+
+// ProcessEvent обрабатывает событие боя
+func (c *Calculator) ProcessEvent(event parser.Event) {
+	c.mu.Lock()
+	defer c.unlock()
+	c.processEvent(event)
+	c.publish()
+}
+
+// ProcessEvents обрабатывает пачку событий и публикует один снапшот на всю пачку
+func (c *Calculator) ProcessEvents(events []parser.Event) {
+	c.mu.Lock()
+	defer c.unlock()
+	for _, event := range events {
+		c.processEvent(event)
+	}
+	c.publish()
+}
+
+// processEvent обрабатывает одно событие; вызывается под c.mu
+func (c *Calculator) processEvent(event parser.Event) {
+	now := event.Time()
+
+	// После завершения сессии следующее событие открывает новую
 	if !c.session.IsActive {
 		c.startNewSession()
 	}
 
-	// Обновляем общую статистику
-	c.session.Stats.TotalDamage += event.Amount
-	c.session.Stats.TotalHits++
-	if event.IsCrit {
-		c.session.Stats.CritHits++
+	// Сессия начинается с первого события
+	if c.session.StartTime.IsZero() {
+		c.session.StartTime = now
 	}
 
-	// Обновляем статистику по способностям
-	if ability, exists := c.session.Abilities[event.Ability]; exists {
-		ability.Damage += event.Amount
-		ability.Hits++
-		if event.IsCrit {
-			ability.Crits++
-		}
-		ability.LastUsed = event.Timestamp
-	} else {
-		c.session.Abilities[event.Ability] = &AbilityStats{
-			Name:     event.Ability,
-			Damage:   event.Amount,
-			Hits:     1,
-			Crits:    boolToInt(event.IsCrit),
-			LastUsed: event.Timestamp,
-		}
+	// Проверяем, нужно ли начать новый бой
+	c.checkCombatStatus(event)
+
+	// Обновляем время последней активности
+	c.session.LastActivity = now
+	if combat := c.activeCombat(); combat != nil {
+		combat.LastActivity = now
 	}
 
-	// Обновляем статистику по целям
-	if target, exists := c.session.Targets[event.Target]; exists {
-		target.Damage += event.Amount
-		target.Hits++
-		if event.IsCrit {
-			target.Crits++
-		}
-		target.LastHit = event.Timestamp
-	} else {
-		c.session.Targets[event.Target] = &TargetStats{
-			Name:    event.Target,
-			Damage:  event.Amount,
-			Hits:    1,
-			Crits:   boolToInt(event.IsCrit),
-			LastHit: event.Timestamp,
-		}
+	parser.Dispatch(event, eventHandler{c})
+
+	// Некоторые политики завершают бой по самому событию, например по убийству
+	if combat := c.activeCombat(); combat != nil && c.policy.Closes(combat, event) {
+		c.endCurrentCombat(now)
 	}
+}
 
-	// Пересчитываем DPS
-	c.updateDPSStats()
+// Tick завершает текущий бой, если по часам калькулятора он истек согласно
+// политике разбиения. Вызывается после обработки пачки событий, чтобы бой из
+// уже прочитанной части лога не оставался активным.
+func (c *Calculator) Tick() {
+	c.mu.Lock()
+	defer c.unlock()
+
+	combat := c.activeCombat()
+	if combat == nil {
+		return
+	}
+
+	if c.policy.Expired(combat, c.clock()) {
+		c.endCurrentCombat(combat.LastActivity)
+		c.publish()
+	}
 }
 
-// processHealEvent обрабатывает событие исцеления
-func (c *Calculator) processHealEvent(event *parser.HealEvent) {
-	if !c.session.IsActive {
-		c.startNewSession()
+// StartCombat вручную начинает новый бой, завершая текущий
+func (c *Calculator) StartCombat() {
+	c.mu.Lock()
+	defer c.unlock()
+
+	now := c.clock()
+	c.endCurrentCombat(now)
+	c.startNewCombat(now)
+	c.publish()
+}
+
+// StopCombat вручную завершает текущий бой
+func (c *Calculator) StopCombat() {
+	c.mu.Lock()
+	defer c.unlock()
+
+	c.endCurrentCombat(c.clock())
+	c.publish()
+}
+
+// eventHandler направляет события парсера в методы калькулятора
+type eventHandler struct {
+	c *Calculator
+}
+
+func (h eventHandler) HandleDamage(e *parser.DamageEvent) {
+	h.c.processDamageEvent(e)
+}
+
+func (h eventHandler) HandleHeal(e *parser.HealEvent) {
+	h.c.processHealEvent(e)
+}
+
+func (h eventHandler) HandleKill(e *parser.KillEvent) {
+	h.c.processKillEvent(e)
+}
+
+func (h eventHandler) HandleBuff(e *parser.BuffEvent) {
+	h.c.processBuffEvent(e)
+}
+
+func (h eventHandler) HandleCombatState(e *parser.CombatStateEvent) {
+	h.c.processCombatStateEvent(e)
+}
+
+// processDamageEvent обрабатывает событие урона
+func (c *Calculator) processDamageEvent(event *parser.DamageEvent) {
+	// Урон по игроку не смешиваем с нанесенным
+	if !event.IsDealt {
+		c.processDamageTaken(event)
+		return
 	}
 
-	// Обновляем общую статистику
-	c.session.Stats.TotalHealing += event.Amount
-	c.session.Stats.TotalHealingHits++
-	if event.IsCrit {
-		c.session.Stats.CritHealing++
+	c.session.addDamage(event)
+	if combat := c.activeCombat(); combat != nil {
+		combat.addDamage(event)
+		combat.record(event.Timestamp, event.Amount, 0, 0)
+		combat.attributeDamage(event)
 	}
 
-	// Обновляем статистику по способностям
-	if ability, exists := c.session.Abilities[event.Ability]; exists {
-		ability.Healing += event.Amount
-		ability.HealingHits++
-		if event.IsCrit {
-			ability.CritHealing++
-		}
-		ability.LastUsed = event.Timestamp
-	} else {
-		c.session.Abilities[event.Ability] = &AbilityStats{
-			Name:        event.Ability,
-			Healing:     event.Amount,
-			HealingHits: 1,
-			CritHealing: boolToInt(event.IsCrit),
-			LastUsed:    event.Timestamp,
-		}
+	// Пересчитываем DPS
+	c.updateDPSStats(event.Timestamp)
+}
+
+// processDamageTaken обрабатывает урон, полученный игроком
+func (c *Calculator) processDamageTaken(event *parser.DamageEvent) {
+	c.session.addDamageTaken(event)
+	if combat := c.activeCombat(); combat != nil {
+		combat.addDamageTaken(event)
+		combat.record(event.Timestamp, 0, 0, event.Amount)
 	}
 
-	// Обновляем статистику по целям
-	if target, exists := c.session.Targets[event.Target]; exists {
-		target.Healing += event.Amount
-		target.HealingHits++
-		if event.IsCrit {
-			target.CritHealing++
-		}
-		target.LastHit = event.Timestamp
-	} else {
-		c.session.Targets[event.Target] = &TargetStats{
-			Name:        event.Target,
-			Healing:     event.Amount,
-			HealingHits: 1,
-			CritHealing: boolToInt(event.IsCrit),
-			LastHit:     event.Timestamp,
-		}
+	// Пересчитываем DTPS
+	c.updateDTPSStats(event.Timestamp)
+}
+
+// processHealEvent обрабатывает событие исцеления
+func (c *Calculator) processHealEvent(event *parser.HealEvent) {
+	c.session.addHeal(event)
+	if combat := c.activeCombat(); combat != nil {
+		combat.addHeal(event)
+		combat.record(event.Timestamp, 0, event.Amount, 0)
 	}
 
 	// Пересчитываем HPS
-	c.updateHPSStats()
+	c.updateHPSStats(event.Timestamp)
 }
 
 // processKillEvent обрабатывает событие убийства
 func (c *Calculator) processKillEvent(event *parser.KillEvent) {
-	if !c.session.IsActive {
-		c.startNewSession()
+	c.session.addKill(event)
+	if combat := c.activeCombat(); combat != nil {
+		combat.addKill(event)
 	}
 
-	// Обновляем общую статистику
-	c.session.Stats.TotalKills++
-
-	// Обновляем статистику по способностям
-	if ability, exists := c.session.Abilities[event.Ability]; exists {
-		ability.Kills++
-		ability.LastUsed = event.Timestamp
-	} else {
-		c.session.Abilities[event.Ability] = &AbilityStats{
-			Name:     event.Ability,
-			Kills:    1,
-			LastUsed: event.Timestamp,
-		}
-	}
+	// Дебаффы с убитой цели больше не действуют
+	c.removeTargetDebuffs(event.Target, event.Timestamp)
+}
 
-	// Обновляем статистику по целям
-	if target, exists := c.session.Targets[event.Target]; exists {
-		target.Kills++
-		target.LastHit = event.Timestamp
-	} else {
-		c.session.Targets[event.Target] = &TargetStats{
-			Name:    event.Target,
-			Kills:   1,
-			LastHit: event.Timestamp,
+// processCombatStateEvent обрабатывает явное изменение состояния боя
+func (c *Calculator) processCombatStateEvent(event *parser.CombatStateEvent) {
+	switch event.State {
+	case "Entered", "Started":
+		if c.activeCombat() == nil {
+			c.startNewCombat(event.Timestamp)
 		}
+	case "Exited", "Ended":
+		c.endCurrentCombat(event.Timestamp)
 	}
 }
 
-// processBuffEvent обрабатывает событие баффа/дебаффа
-func (c *Calculator) processBuffEvent(event *parser.BuffEvent) {
-	// Пока что просто логируем баффы, можно добавить статистику по баффам
-	// В будущем можно добавить отслеживание времени действия баффов, их эффективности и т.д.
-}
-
 // updateDPSStats пересчитывает статистику DPS за текущий бой
-func (c *Calculator) updateDPSStats() {
-	if c.session.CurrentCombat == nil || !c.session.CurrentCombat.IsActive {
+func (c *Calculator) updateDPSStats(now time.Time) {
+	combat := c.activeCombat()
+	if combat == nil {
 		// Если нет активного боя, DPS = 0
 		c.session.DPSStats.CurrentDPS = 0
 		return
 	}
 
-	// Рассчитываем урон за текущий бой
-	damageInCombat := 0
-	now := time.Now()
-	combatStartTime := c.session.CurrentCombat.StartTime
+	stats := &c.session.DPSStats
+	stats.Window5s = combat.rate(now, ShortWindow, bucketDamage)
+	stats.Window15s = combat.rate(now, MediumWindow, bucketDamage)
+	stats.Window30s = combat.rate(now, LongWindow, bucketDamage)
+	stats.CurrentDPS = stats.Window5s
 
-	for _, event := range c.session.RecentEvents {
-		// События должны быть в рамках текущего боя
-		if event.Timestamp.After(combatStartTime) {
-			if damageEvent, ok := event.Event.(*parser.DamageEvent); ok {
-				if damageEvent.IsDealt {
-					damageInCombat += damageEvent.Amount
-				}
-			}
-		}
+	// Среднее = урон за бой / длительность боя
+	stats.AvgDPS = perSecond(combat.Stats.TotalDamage, now.Sub(combat.StartTime))
+
+	// Обновляем максимум, только когда окно заполнено
+	if combat.windowFull(now, ShortWindow) && stats.CurrentDPS > stats.MaxDPS {
+		stats.MaxDPS = stats.CurrentDPS
 	}
 
-	// DPS = урон за бой / длительность боя
-	combatDuration := now.Sub(combatStartTime).Seconds()
-	if combatDuration > 0 {
-		c.session.DPSStats.CurrentDPS = float64(damageInCombat) / combatDuration
-	} else {
-		c.session.DPSStats.CurrentDPS = 0
+	stats.TotalDamage = c.session.Stats.TotalDamage
+	stats.Duration = now.Sub(c.session.StartTime)
+}
+
+// updateDTPSStats пересчитывает статистику полученного урона в секунду за текущий бой
+func (c *Calculator) updateDTPSStats(now time.Time) {
+	combat := c.activeCombat()
+	if combat == nil {
+		// Если нет активного боя, DTPS = 0
+		c.session.DamageTaken.CurrentDTPS = 0
+		return
 	}
 
-	// Обновляем максимум
-	if c.session.DPSStats.CurrentDPS > c.session.DPSStats.MaxDPS {
-		c.session.DPSStats.MaxDPS = c.session.DPSStats.CurrentDPS
+	stats := &c.session.DamageTaken
+	stats.CurrentDTPS = combat.rate(now, ShortWindow, bucketDamageTaken)
+
+	// Среднее = полученный урон за бой / длительность боя
+	stats.AvgDTPS = perSecond(combat.DamageTaken.TotalDamage, now.Sub(combat.StartTime))
+
+	// Обновляем максимум, только когда окно заполнено
+	if combat.windowFull(now, ShortWindow) && stats.CurrentDTPS > stats.MaxDTPS {
+		stats.MaxDTPS = stats.CurrentDTPS
 	}
 
-	c.session.DPSStats.TotalDamage = c.session.Stats.TotalDamage
-	c.session.DPSStats.Duration = time.Since(c.session.StartTime)
+	stats.Duration = now.Sub(c.session.StartTime)
 }
 
 // updateHPSStats пересчитывает статистику HPS за текущий бой
-func (c *Calculator) updateHPSStats() {
-	if c.session.CurrentCombat == nil || !c.session.CurrentCombat.IsActive {
+func (c *Calculator) updateHPSStats(now time.Time) {
+	combat := c.activeCombat()
+	if combat == nil {
 		// Если нет активного боя, HPS = 0
 		c.session.HPSStats.CurrentHPS = 0
 		return
 	}
 
-	// Рассчитываем исцеление за текущий бой
-	healingInCombat := 0
-	now := time.Now()
-
-	for _, event := range c.session.RecentEvents {
-		// События должны быть в рамках текущего боя
-		if event.Timestamp.After(c.session.CurrentCombat.StartTime) &&
-			(c.session.CurrentCombat.IsActive || event.Timestamp.Before(c.session.CurrentCombat.EndTime)) {
-			if healEvent, ok := event.Event.(*parser.HealEvent); ok && !healEvent.IsDealt {
-				healingInCombat += healEvent.Amount
-			}
-		}
-	}
+	stats := &c.session.HPSStats
+	stats.Window5s = combat.rate(now, ShortWindow, bucketHealing)
+	stats.Window15s = combat.rate(now, MediumWindow, bucketHealing)
+	stats.Window30s = combat.rate(now, LongWindow, bucketHealing)
+	stats.CurrentHPS = stats.Window5s
 
-	// HPS = исцеление за бой / длительность боя
-	combatDuration := now.Sub(c.session.CurrentCombat.StartTime).Seconds()
-	if combatDuration > 0 {
-		c.session.HPSStats.CurrentHPS = float64(healingInCombat) / combatDuration
-	} else {
-		c.session.HPSStats.CurrentHPS = 0
-	}
+	// Среднее = исцеление за бой / длительность боя
+	stats.AvgHPS = perSecond(combat.Stats.TotalHealing, now.Sub(combat.StartTime))
 
-	// Обновляем максимум
-	if c.session.HPSStats.CurrentHPS > c.session.HPSStats.MaxHPS {
-		c.session.HPSStats.MaxHPS = c.session.HPSStats.CurrentHPS
+	// Обновляем максимум, только когда окно заполнено
+	if combat.windowFull(now, ShortWindow) && stats.CurrentHPS > stats.MaxHPS {
+		stats.MaxHPS = stats.CurrentHPS
 	}
 
-	c.session.HPSStats.TotalHealing = c.session.Stats.TotalHealing
-	c.session.HPSStats.Duration = time.Since(c.session.StartTime)
+	stats.TotalHealing = c.session.Stats.TotalHealing
+	stats.Duration = now.Sub(c.session.StartTime)
 }
 
 // checkCombatStatus проверяет статус боя и при необходимости начинает новый
-func (c *Calculator) checkCombatStatus(now time.Time) {
+func (c *Calculator) checkCombatStatus(event parser.Event) {
+	now := event.Time()
+
+	// Бой закончился на последнем событии, а не в момент прихода нового
+	if combat := c.activeCombat(); combat != nil && c.policy.Expired(combat, now) {
+		c.endCurrentCombat(combat.LastActivity)
+	}
+
 	// Если нет активного боя, начинаем новый
-	if c.session.CurrentCombat == nil || !c.session.CurrentCombat.IsActive {
+	if c.activeCombat() == nil && c.policy.Opens(event) {
 		c.startNewCombat(now)
-		return
 	}
+}
 
-	// Проверяем, прошло ли 10 секунд без активности
-	if now.Sub(c.session.LastActivity) >= 10*time.Second {
-		// Завершаем текущий бой
-		c.endCurrentCombat(now)
-		// Не начинаем новый бой автоматически - ждем следующего события
+// activeCombat возвращает текущий бой, если он активен
+func (c *Calculator) activeCombat() *Combat {
+	if c.session.CurrentCombat == nil || !c.session.CurrentCombat.IsActive {
+		return nil
 	}
+	return c.session.CurrentCombat
 }
 
 // startNewCombat начинает новый бой
 func (c *Calculator) startNewCombat(now time.Time) {
 	c.session.CurrentCombat = &Combat{
-		ID:        generateSessionID(),
-		StartTime: now,
-		IsActive:  true,
+		ID:           c.newCombatID(now),
+		StartTime:    now,
+		IsActive:     true,
+		LastActivity: now,
+		Buffs:        make(map[BuffKey]*BuffStats),
+		Breakdown:    newBreakdown(),
+	}
+	c.session.CurrentCombat.openBuffs(c.session.ActiveBuffs, now)
+	c.logf("Started new combat: %s\n", c.session.CurrentCombat.ID)
+
+	if c.onCombatStart != nil {
+		notify, started := c.onCombatStart, c.session.CurrentCombat.clone()
+		c.pending = append(c.pending, func() { notify(started) })
 	}
-	fmt.Printf("Started new combat: %s\n", c.session.CurrentCombat.ID)
 }
 
 // endCurrentCombat завершает текущий бой
 func (c *Calculator) endCurrentCombat(now time.Time) {
-	if c.session.CurrentCombat != nil && c.session.CurrentCombat.IsActive {
-		c.session.CurrentCombat.EndTime = now
-		c.session.CurrentCombat.IsActive = false
-		c.session.CurrentCombat.Duration = now.Sub(c.session.CurrentCombat.StartTime)
-		fmt.Printf("Ended combat: %s, Duration: %v\n", c.session.CurrentCombat.ID, c.session.CurrentCombat.Duration)
-	}
-}
-
-// addRecentEvent добавляет событие в список недавних событий
-func (c *Calculator) addRecentEvent(event interface{}) {
-	now := time.Now()
-	c.session.RecentEvents = append(c.session.RecentEvents, CombatEvent{
-		Timestamp: now,
-		Event:     event,
-	})
-
-	// Удаляем события старше 2 минут (оставляем буфер)
-	twoMinutesAgo := now.Add(-2 * time.Minute)
-	var recentEvents []CombatEvent
-	for _, evt := range c.session.RecentEvents {
-		if evt.Timestamp.After(twoMinutesAgo) {
-			recentEvents = append(recentEvents, evt)
+	if combat := c.activeCombat(); combat != nil {
+		combat.EndTime = now
+		combat.IsActive = false
+		combat.Duration = now.Sub(combat.StartTime)
+		combat.Stats.StartTime = combat.StartTime
+		combat.Stats.EndTime = combat.EndTime
+		combat.Stats.Duration = combat.Duration
+		combat.closeBuffs(now)
+		c.session.DPSStats.CurrentDPS = 0
+		c.session.DPSStats.Window5s = 0
+		c.session.DPSStats.Window15s = 0
+		c.session.DPSStats.Window30s = 0
+		c.session.HPSStats.CurrentHPS = 0
+		c.session.HPSStats.Window5s = 0
+		c.session.HPSStats.Window15s = 0
+		c.session.HPSStats.Window30s = 0
+		c.session.DamageTaken.CurrentDTPS = 0
+		c.logf("Ended combat: %s, Duration: %v\n", combat.ID, combat.Duration)
+
+		// Бои без урона и исцеления (например, только баффы) в историю не попадают
+		if !combat.isEmpty() {
+			c.session.Encounters = append(c.session.Encounters, combat)
+		}
+
+		if c.onCombatEnd != nil {
+			notify := c.onCombatEnd
+			c.pending = append(c.pending, func() { notify(combat) })
+		}
+	}
+}
+
+// newCombatID создает идентификатор боя, уникальный в пределах сессии
+func (c *Calculator) newCombatID(now time.Time) string {
+	base := generateSessionID(now)
+	id := base
+	for n := 2; findEncounter(c.session, id) != nil; n++ {
+		id = fmt.Sprintf("%s-%d", base, n)
+	}
+	return id
+}
+
+// GetEncounters возвращает завершенные бои и текущий, если он активен.
+// Бои берутся из последнего снапшота и не должны изменяться.
+func (c *Calculator) GetEncounters() []*Combat {
+	session := c.GetSession()
+	encounters := make([]*Combat, 0, len(session.Encounters)+1)
+	encounters = append(encounters, session.Encounters...)
+	if combat := session.CurrentCombat; combat != nil && combat.IsActive {
+		encounters = append(encounters, combat)
+	}
+	return encounters
+}
+
+// GetEncounter возвращает бой из последнего снапшота по идентификатору или nil
+func (c *Calculator) GetEncounter(id string) *Combat {
+	return findEncounter(c.GetSession(), id)
+}
+
+// findEncounter ищет бой сессии по идентификатору
+func findEncounter(session *CombatSession, id string) *Combat {
+	if combat := session.CurrentCombat; combat != nil && combat.IsActive && combat.ID == id {
+		return combat
+	}
+	for _, combat := range session.Encounters {
+		if combat.ID == id {
+			return combat
 		}
 	}
-	c.session.RecentEvents = recentEvents
+	return nil
 }
 
-// startNewSession начинает новую сессию боя
+// startNewSession начинает новую сессию боя. Время начала выставляется
+// по первому событию, попавшему в сессию.
 func (c *Calculator) startNewSession() {
 	c.session = &CombatSession{
-		ID:        generateSessionID(),
-		StartTime: time.Now(),
-		IsActive:  true,
-		Stats: CombatStats{
-			TotalDamage:      0,
-			TotalHealing:     0,
-			TotalHits:        0,
-			TotalHealingHits: 0,
-			CritHits:         0,
-			CritHealing:      0,
-			TotalKills:       0,
-		},
-		DPSStats: DPSStats{
-			CurrentDPS:  0,
-			MaxDPS:      0,
-			TotalDamage: 0,
-			Duration:    0,
-		},
-		HPSStats: HPSStats{
-			CurrentHPS:   0,
-			MaxHPS:       0,
-			TotalHealing: 0,
-			Duration:     0,
-		},
-		Abilities:    make(map[string]*AbilityStats),
-		Targets:      make(map[string]*TargetStats),
-		RecentEvents: make([]CombatEvent, 0),
-		LastActivity: time.Now(),
-	}
-}
-
-// GetSession возвращает текущую сессию
+		ID:          generateSessionID(c.clock()),
+		IsActive:    true,
+		Breakdown:   newBreakdown(),
+		ActiveBuffs: make(map[BuffKey]time.Time),
+	}
+}
+
+// GetSession возвращает снапшот сессии на момент последнего изменения.
+// Снапшот неизменяем и может читаться без блокировок; изменять его нельзя.
 func (c *Calculator) GetSession() *CombatSession {
-	return c.session
+	return c.snapshot.Load()
 }
 
 // ResetSession сбрасывает текущую сессию
 func (c *Calculator) ResetSession() {
+	c.mu.Lock()
+	defer c.unlock()
+
 	c.startNewSession()
+	c.publish()
 }
 
 // EndSession завершает текущую сессию
 func (c *Calculator) EndSession() {
-	c.session.EndTime = time.Now()
+	c.mu.Lock()
+	defer c.unlock()
+
+	c.endCurrentCombat(c.session.LastActivity)
+	c.session.EndTime = c.session.LastActivity
 	c.session.IsActive = false
 	c.session.Stats.Duration = c.session.EndTime.Sub(c.session.StartTime)
+	c.publish()
 }
 
 // Вспомогательные функции
-func generateSessionID() string {
-	return time.Now().Format("20060102150405")
+func generateSessionID(t time.Time) string {
+	return t.Format("20060102150405")
+}
+
+// perSecond делит значение на длительность в секундах
+func perSecond(value int, duration time.Duration) float64 {
+	if duration <= 0 {
+		return 0
+	}
+	return float64(value) / duration.Seconds()
 }
 
 func boolToInt(b bool) int {
@@ -0,0 +1,226 @@
+package metrics
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"aocdpsmetr/internal/parser"
+)
+
+var testStart = time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+// hit создает событие нанесенного урона через offset после testStart
+func hit(offset time.Duration, amount int) *parser.DamageEvent {
+	return &parser.DamageEvent{
+		Timestamp: testStart.Add(offset),
+		Amount:    amount,
+		Target:    "Goblin",
+		Source:    "Player",
+		Ability:   "Slash",
+		IsDealt:   true,
+	}
+}
+
+func TestMaxDPSCountsFullWindowsOnly(t *testing.T) {
+	c := NewCalculator()
+	c.SetClock(func() time.Time { return testStart })
+
+	// Сильный первый удар, затем по 100 урона в секунду
+	c.ProcessEvent(hit(0, 1200))
+	for i := 1; i < 10; i++ {
+		c.ProcessEvent(hit(time.Duration(i)*time.Second, 100))
+	}
+
+	// Лучшее полное окно - секунды 0..4: (1200 + 4*100) / 5
+	if got := c.GetSession().DPSStats.MaxDPS; got != 320 {
+		t.Errorf("MaxDPS = %v, want 320", got)
+	}
+}
+
+// Запускать с -race: один писатель и читатели снапшотов работают одновременно
+func TestConcurrentAccess(t *testing.T) {
+	c := NewCalculator()
+	c.SetClock(func() time.Time { return testStart })
+
+	done := make(chan struct{})
+	var readers sync.WaitGroup
+	read := func(fn func()) {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					fn()
+				}
+			}
+		}()
+	}
+
+	read(func() {
+		session := c.GetSession()
+		for _, ability := range session.Abilities {
+			_ = ability.Damage
+		}
+	})
+	read(func() {
+		for _, combat := range c.GetEncounters() {
+			_ = combat.Stats.TotalDamage
+		}
+	})
+	read(c.ResetSession)
+
+	// Паузы больше таймаута простоя, чтобы бои завершались и попадали в историю
+	for i := 0; i < 2000; i++ {
+		c.ProcessEvent(hit(time.Duration(i)*(DefaultIdleTimeout+time.Second), 100))
+	}
+	close(done)
+	readers.Wait()
+}
+
+func TestSnapshotCopiesChangedEntriesOnly(t *testing.T) {
+	c := NewCalculator()
+	c.SetClock(func() time.Time { return testStart })
+
+	c.ProcessEvent(hit(0, 100))
+	stab := hit(time.Second, 50)
+	stab.Ability = "Stab"
+	c.ProcessEvent(stab)
+	before := c.GetSession()
+
+	c.ProcessEvent(hit(2*time.Second, 200))
+	after := c.GetSession()
+
+	// Прежний снапшот не меняется
+	if got := before.Abilities["Slash"].Damage; got != 100 {
+		t.Errorf("old snapshot Slash damage = %d, want 100", got)
+	}
+	if got := before.CurrentCombat.Abilities["Slash"].Hits; got != 1 {
+		t.Errorf("old snapshot combat Slash hits = %d, want 1", got)
+	}
+	if got := after.Abilities["Slash"].Damage; got != 300 {
+		t.Errorf("Slash damage = %d, want 300", got)
+	}
+	if got := after.CurrentCombat.Abilities["Slash"].HitCounts; len(got) == 0 || len(before.CurrentCombat.Abilities["Slash"].HitCounts) == len(got) {
+		t.Errorf("HitCounts before = %v, after = %v", before.CurrentCombat.Abilities["Slash"].HitCounts, got)
+	}
+
+	// Неизмененные записи разделяются между снапшотами
+	if before.Abilities["Stab"] != after.Abilities["Stab"] {
+		t.Error("unchanged session ability was copied")
+	}
+	if before.CurrentCombat.Abilities["Stab"] != after.CurrentCombat.Abilities["Stab"] {
+		t.Error("unchanged combat ability was copied")
+	}
+
+	// После сброса сессии снапшот не содержит старых записей
+	c.ResetSession()
+	if n := len(c.GetSession().Abilities); n != 0 {
+		t.Errorf("%d abilities after reset", n)
+	}
+}
+
+func TestCombatsFollowEventTime(t *testing.T) {
+	// Два боя, разделенные паузой больше таймаута простоя
+	events := []parser.Event{
+		hit(0, 100), hit(time.Second, 100), hit(3*time.Second, 200),
+		hit(33*time.Second, 300), hit(35*time.Second, 300),
+	}
+
+	// Живые часы давно ушли вперед от времени лога, но бои считаются по событиям
+	live := NewCalculator()
+	live.ProcessEvents(events)
+
+	now := testStart.Add(35 * time.Second)
+	c := NewCalculator()
+	c.SetClock(func() time.Time { return now })
+	c.ProcessEvents(events)
+
+	for name, calc := range map[string]*Calculator{"live": live, "replay": c} {
+		encounters := calc.GetEncounters()
+		if len(encounters) != 2 {
+			t.Fatalf("%s: got %d encounters, want 2", name, len(encounters))
+		}
+		first := encounters[0]
+		if !first.StartTime.Equal(testStart) || first.Duration != 3*time.Second || first.IsActive {
+			t.Errorf("%s: first combat %v..%v (%v), active %v", name, first.StartTime, first.EndTime, first.Duration, first.IsActive)
+		}
+		if first.Stats.TotalDamage != 400 {
+			t.Errorf("%s: first combat damage = %d, want 400", name, first.Stats.TotalDamage)
+		}
+		if second := encounters[1]; !second.StartTime.Equal(testStart.Add(33*time.Second)) || second.Stats.TotalDamage != 600 {
+			t.Errorf("%s: second combat starts %v with %d damage", name, second.StartTime, second.Stats.TotalDamage)
+		}
+		if got := calc.GetSession().Elapsed(); got != 35*time.Second {
+			t.Errorf("%s: session elapsed = %v, want 35s", name, got)
+		}
+	}
+
+	// Таймаут простоя отсчитывается по часам калькулятора от последнего события
+	now = testStart.Add(44 * time.Second)
+	c.Tick()
+	if combat := c.GetSession().CurrentCombat; !combat.IsActive {
+		t.Fatal("combat ended before the idle timeout")
+	}
+	now = testStart.Add(45 * time.Second)
+	c.Tick()
+	combat := c.GetSession().CurrentCombat
+	if combat.IsActive || !combat.EndTime.Equal(testStart.Add(35*time.Second)) || combat.Duration != 2*time.Second {
+		t.Errorf("after timeout: active %v, end %v, duration %v", combat.IsActive, combat.EndTime, combat.Duration)
+	}
+}
+
+func TestEncounterHistory(t *testing.T) {
+	c := NewCalculator()
+	var now time.Time
+	c.SetClock(func() time.Time { return now })
+
+	// Три боя с разными целями и способностями
+	gap := DefaultIdleTimeout + time.Second
+	for i, name := range []string{"Goblin", "Orc", "Troll"} {
+		start := time.Duration(i) * 2 * gap
+		for j := 0; j <= i; j++ {
+			event := hit(start+time.Duration(j)*time.Second, 100*(i+1))
+			event.Target, event.Ability = name, name+"Bane"
+			c.ProcessEvent(event)
+			now = event.Timestamp
+		}
+	}
+	before := c.GetEncounters()
+
+	c.StopCombat()
+	encounters := c.GetEncounters()
+	if len(encounters) != 3 {
+		t.Fatalf("got %d encounters, want 3", len(encounters))
+	}
+
+	for i, combat := range encounters {
+		name := []string{"Goblin", "Orc", "Troll"}[i]
+		if combat.IsActive {
+			t.Errorf("%s: combat still active", name)
+		}
+		if len(combat.Targets) != 1 || combat.Targets[name] == nil || combat.Abilities[name+"Bane"] == nil {
+			t.Errorf("%s: targets %v, abilities %v", name, combat.Targets, combat.Abilities)
+		}
+		if want := (i + 1) * (i + 1) * 100; combat.Stats.TotalDamage != want {
+			t.Errorf("%s: damage = %d, want %d", name, combat.Stats.TotalDamage, want)
+		}
+		if want := time.Duration(i) * time.Second; combat.Duration != want || combat.Stats.Duration != want {
+			t.Errorf("%s: duration = %v, want %v", name, combat.Duration, want)
+		}
+		if got := c.GetEncounter(combat.ID); got != combat {
+			t.Errorf("GetEncounter(%s) = %v", combat.ID, got)
+		}
+	}
+
+	// Бои из старого снапшота не меняются после завершения текущего боя
+	if last := before[len(before)-1]; !last.IsActive {
+		t.Error("old snapshot sees the combat ended")
+	}
+	if c.GetEncounter("missing") != nil {
+		t.Error("GetEncounter returned a combat for an unknown id")
+	}
+}
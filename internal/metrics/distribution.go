@@ -0,0 +1,95 @@
+package metrics
+
+// DefaultHistogramBins число корзин гистограммы по умолчанию
+const DefaultHistogramBins = 10
+
+// HistogramBin представляет корзину гистограммы размеров ударов
+type HistogramBin struct {
+	Min   int
+	Max   int
+	Count int
+}
+
+// recordHit учитывает размер удара для распределения
+func (a *AbilityStats) recordHit(amount int, isCrit bool) {
+	if a.HitCounts == nil {
+		a.HitCounts = make(map[int]int)
+		a.MinHit = amount
+		a.MaxHit = amount
+	}
+
+	if amount < a.MinHit {
+		a.MinHit = amount
+	}
+	if amount > a.MaxHit {
+		a.MaxHit = amount
+	}
+	if isCrit {
+		a.CritDamage += amount
+	}
+	a.HitCounts[amount]++
+}
+
+// AverageHit возвращает средний удар
+func (a *AbilityStats) AverageHit() float64 {
+	return average(a.Damage, a.Hits)
+}
+
+// AverageNonCrit возвращает средний удар без крита
+func (a *AbilityStats) AverageNonCrit() float64 {
+	return average(a.Damage-a.CritDamage, a.Hits-a.Crits)
+}
+
+// AverageCrit возвращает средний критический удар
+func (a *AbilityStats) AverageCrit() float64 {
+	return average(a.CritDamage, a.Crits)
+}
+
+// CritMultiplier возвращает наблюдаемый множитель крита: средний крит к среднему обычному удару
+func (a *AbilityStats) CritMultiplier() float64 {
+	nonCrit := a.AverageNonCrit()
+	if nonCrit == 0 {
+		return 0
+	}
+	return a.AverageCrit() / nonCrit
+}
+
+// Histogram разбивает удары на bins корзин равной ширины от MinHit до MaxHit
+func (a *AbilityStats) Histogram(bins int) []HistogramBin {
+	if len(a.HitCounts) == 0 || bins <= 0 {
+		return nil
+	}
+
+	// Корзин не больше, чем возможных значений
+	span := a.MaxHit - a.MinHit + 1
+	if bins > span {
+		bins = span
+	}
+	width := (span + bins - 1) / bins
+	// Ширина округляется вверх, поэтому корзин может понадобиться меньше
+	bins = (span + width - 1) / width
+
+	histogram := make([]HistogramBin, bins)
+	for i := range histogram {
+		histogram[i].Min = a.MinHit + i*width
+		histogram[i].Max = histogram[i].Min + width - 1
+	}
+	histogram[bins-1].Max = a.MaxHit
+
+	for amount, count := range a.HitCounts {
+		index := (amount - a.MinHit) / width
+		if index >= bins {
+			index = bins - 1
+		}
+		histogram[index].Count += count
+	}
+
+	return histogram
+}
+
+func average(sum, count int) float64 {
+	if count == 0 {
+		return 0
+	}
+	return float64(sum) / float64(count)
+}
@@ -0,0 +1,48 @@
+package metrics
+
+import "testing"
+
+func TestHistogramBinsCoverRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		hits     []int
+		bins     int
+		wantBins int
+	}{
+		{name: "even span", hits: []int{100, 109}, bins: 10, wantBins: 10},
+		{name: "uneven span", hits: []int{100, 105, 110}, bins: 10, wantBins: 6},
+		{name: "span smaller than bins", hits: []int{100, 102}, bins: 10, wantBins: 3},
+		{name: "single value", hits: []int{50, 50}, bins: 10, wantBins: 1},
+		{name: "wide span", hits: []int{1, 1000, 501}, bins: 7, wantBins: 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ability := &AbilityStats{Name: "Slash"}
+			for _, amount := range tt.hits {
+				ability.recordHit(amount, false)
+			}
+
+			histogram := ability.Histogram(tt.bins)
+			if len(histogram) != tt.wantBins {
+				t.Fatalf("got %d bins, want %d: %+v", len(histogram), tt.wantBins, histogram)
+			}
+
+			// Корзины идут подряд от MinHit до MaxHit и содержат все удары
+			next, total := ability.MinHit, 0
+			for _, bin := range histogram {
+				if bin.Min != next || bin.Max < bin.Min || bin.Max > ability.MaxHit {
+					t.Fatalf("invalid bin %+v (expected to start at %d, max hit %d)", bin, next, ability.MaxHit)
+				}
+				next = bin.Max + 1
+				total += bin.Count
+			}
+			if next != ability.MaxHit+1 {
+				t.Errorf("bins end at %d, want %d", next-1, ability.MaxHit)
+			}
+			if total != len(tt.hits) {
+				t.Errorf("bins hold %d hits, want %d", total, len(tt.hits))
+			}
+		})
+	}
+}
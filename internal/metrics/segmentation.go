@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"fmt"
+	"time"
+
+	"aocdpsmetr/internal/parser"
+)
+
+// DefaultIdleTimeout время без активности, после которого бой считается завершенным
+const DefaultIdleTimeout = 10 * time.Second
+
+// SegmentationMode определяет правило разбиения лога на бои
+type SegmentationMode string
+
+const (
+	// SegmentIdle - бой заканчивается после паузы без событий
+	SegmentIdle SegmentationMode = "idle"
+	// SegmentKills - бой заканчивается, когда убиты все цели, по которым шел урон
+	SegmentKills SegmentationMode = "kills"
+	// SegmentManual - бой начинается и заканчивается только вручную
+	SegmentManual SegmentationMode = "manual"
+)
+
+// SegmentationPolicy решает, когда начинается и заканчивается бой
+type SegmentationPolicy interface {
+	// Mode возвращает режим политики
+	Mode() SegmentationMode
+	// Opens сообщает, начинает ли событие новый бой, если активного боя нет
+	Opens(event parser.Event) bool
+	// Expired сообщает, что активный бой закончился к моменту now
+	Expired(combat *Combat, now time.Time) bool
+	// Closes сообщает, что бой закончился сразу после события
+	Closes(combat *Combat, event parser.Event) bool
+}
+
+// NewSegmentationPolicy создает политику по режиму. Таймаут используется
+// режимами idle и kills; нулевое значение заменяется значением по умолчанию.
+func NewSegmentationPolicy(mode SegmentationMode, timeout time.Duration) (SegmentationPolicy, error) {
+	if timeout <= 0 {
+		timeout = DefaultIdleTimeout
+	}
+
+	switch mode {
+	case SegmentIdle:
+		return IdlePolicy{Timeout: timeout}, nil
+	case SegmentKills:
+		return KillsPolicy{Timeout: timeout}, nil
+	case SegmentManual:
+		return ManualPolicy{}, nil
+	default:
+		return nil, fmt.Errorf("unknown segmentation mode: %q", mode)
+	}
+}
+
+// IdlePolicy завершает бой после паузы без событий
+type IdlePolicy struct {
+	Timeout time.Duration
+}
+
+func (p IdlePolicy) Mode() SegmentationMode { return SegmentIdle }
+
+func (p IdlePolicy) Opens(event parser.Event) bool { return opensCombat(event) }
+
+func (p IdlePolicy) Expired(combat *Combat, now time.Time) bool {
+	return now.Sub(combat.LastActivity) >= p.Timeout
+}
+
+func (p IdlePolicy) Closes(combat *Combat, event parser.Event) bool { return false }
+
+// KillsPolicy завершает бой, когда каждая цель, по которой шел урон, убита.
+// Если цель ушла живой, бой все равно закрывается после паузы Timeout.
+// В логе нет идентификаторов мобов, поэтому цели различаются только по имени:
+// несколько одноименных мобов считаются одной целью, и бой заканчивается
+// после убийства первого из них.
+type KillsPolicy struct {
+	Timeout time.Duration
+}
+
+func (p KillsPolicy) Mode() SegmentationMode { return SegmentKills }
+
+func (p KillsPolicy) Opens(event parser.Event) bool { return opensCombat(event) }
+
+func (p KillsPolicy) Expired(combat *Combat, now time.Time) bool {
+	return now.Sub(combat.LastActivity) >= p.Timeout
+}
+
+func (p KillsPolicy) Closes(combat *Combat, event parser.Event) bool {
+	if event.Kind() != parser.KindKill {
+		return false
+	}
+
+	engaged := 0
+	for _, target := range combat.Targets {
+		if target.Damage == 0 {
+			continue
+		}
+		engaged++
+		if target.Kills == 0 {
+			return false
+		}
+	}
+	return engaged > 0
+}
+
+// ManualPolicy не начинает и не завершает бои сама - только по команде
+type ManualPolicy struct{}
+
+func (p ManualPolicy) Mode() SegmentationMode { return SegmentManual }
+
+func (p ManualPolicy) Opens(event parser.Event) bool { return false }
+
+func (p ManualPolicy) Expired(combat *Combat, now time.Time) bool { return false }
+
+func (p ManualPolicy) Closes(combat *Combat, event parser.Event) bool { return false }
+
+// opensCombat сообщает, что событие относится к бою: баффы и смена
+// состояния сами по себе бой не начинают
+func opensCombat(event parser.Event) bool {
+	switch event.Kind() {
+	case parser.KindDamage, parser.KindHeal, parser.KindKill:
+		return true
+	default:
+		return false
+	}
+}
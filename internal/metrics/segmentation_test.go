@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"aocdpsmetr/internal/parser"
+)
+
+// newPolicyCalculator создает калькулятор с политикой mode и часами, которые возвращают *now
+func newPolicyCalculator(t *testing.T, mode SegmentationMode, timeout time.Duration, now *time.Time) *Calculator {
+	t.Helper()
+	policy, err := NewSegmentationPolicy(mode, timeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewCalculator()
+	c.SetSegmentationPolicy(policy)
+	c.SetClock(func() time.Time { return *now })
+	return c
+}
+
+// kill создает смертельный удар по цели: урон и убийство
+func kill(offset time.Duration, target string) []parser.Event {
+	damage := hit(offset, 100)
+	damage.Target, damage.IsLethal = target, true
+	return []parser.Event{damage, &parser.KillEvent{
+		Timestamp: damage.Timestamp,
+		Target:    target,
+		Source:    damage.Source,
+		Ability:   damage.Ability,
+		Damage:    damage.Amount,
+	}}
+}
+
+func TestIdlePolicyTimeout(t *testing.T) {
+	now := testStart
+	c := newPolicyCalculator(t, SegmentIdle, 5*time.Second, &now)
+
+	// Пауза 4 с короче таймаута, пауза 6 с - длиннее
+	c.ProcessEvents([]parser.Event{hit(0, 100), hit(4*time.Second, 100), hit(10*time.Second, 100)})
+
+	encounters := c.GetEncounters()
+	if len(encounters) != 2 {
+		t.Fatalf("got %d encounters, want 2", len(encounters))
+	}
+	if first := encounters[0]; first.Duration != 4*time.Second || first.Stats.TotalHits != 2 {
+		t.Errorf("first combat: duration %v, %d hits", first.Duration, first.Stats.TotalHits)
+	}
+	if second := encounters[1]; !second.IsActive || !second.StartTime.Equal(testStart.Add(10*time.Second)) {
+		t.Errorf("second combat: active %v, start %v", second.IsActive, second.StartTime)
+	}
+}
+
+func TestKillsPolicyEndsOnLastKill(t *testing.T) {
+	now := testStart
+	c := newPolicyCalculator(t, SegmentKills, time.Minute, &now)
+
+	orc := hit(time.Second, 100)
+	orc.Target = "Orc"
+	c.ProcessEvents([]parser.Event{hit(0, 100), orc})
+	c.ProcessEvents(kill(2*time.Second, "Goblin"))
+	if combat := c.GetSession().CurrentCombat; !combat.IsActive {
+		t.Fatal("combat ended while the orc is alive")
+	}
+
+	c.ProcessEvents(kill(5*time.Second, "Orc"))
+	combat := c.GetSession().CurrentCombat
+	if combat.IsActive || !combat.EndTime.Equal(testStart.Add(5*time.Second)) {
+		t.Fatalf("after the last kill: active %v, end %v", combat.IsActive, combat.EndTime)
+	}
+	if combat.Stats.TotalKills != 2 {
+		t.Errorf("TotalKills = %d, want 2", combat.Stats.TotalKills)
+	}
+
+	// Цель, ушедшая живой, закрывает бой по таймауту
+	c.ProcessEvent(hit(10*time.Second, 100))
+	now = testStart.Add(10*time.Second + time.Minute)
+	c.Tick()
+	if encounters := c.GetEncounters(); len(encounters) != 2 || encounters[1].IsActive {
+		t.Errorf("got %d encounters after the timeout", len(encounters))
+	}
+}
+
+func TestManualPolicy(t *testing.T) {
+	now := testStart
+	c := newPolicyCalculator(t, SegmentManual, 0, &now)
+
+	// Без команды события не начинают бой, но входят в сессию
+	c.ProcessEvent(hit(0, 100))
+	if combat := c.GetSession().CurrentCombat; combat != nil {
+		t.Fatalf("combat %s started without a command", combat.ID)
+	}
+
+	now = testStart.Add(time.Second)
+	c.StartCombat()
+	c.ProcessEvents([]parser.Event{hit(2*time.Second, 100), hit(time.Hour, 100)})
+	now = testStart.Add(2 * time.Hour)
+	c.Tick()
+	if combat := c.GetSession().CurrentCombat; !combat.IsActive {
+		t.Fatal("manual combat ended by a pause")
+	}
+
+	c.StopCombat()
+	combat := c.GetSession().CurrentCombat
+	if combat.IsActive || combat.Duration != 2*time.Hour-time.Second || combat.Stats.TotalHits != 2 {
+		t.Errorf("combat: active %v, duration %v, %d hits", combat.IsActive, combat.Duration, combat.Stats.TotalHits)
+	}
+	if total := c.GetSession().Stats.TotalHits; total != 3 {
+		t.Errorf("session hits = %d, want 3", total)
+	}
+}
@@ -0,0 +1,161 @@
+package metrics
+
+import "maps"
+
+// publish сохраняет неизменяемую копию текущей сессии для читателей.
+// Вызывается под c.mu после каждого изменения состояния.
+func (c *Calculator) publish() {
+	c.snapshot.Store(c.session.snapshot(c.snapshot.Load()))
+	if c.onChange != nil {
+		c.pending = append(c.pending, c.onChange)
+	}
+}
+
+// unlock снимает блокировку и вызывает отложенные уведомления. Обработчики
+// вызываются без блокировки, поэтому могут обращаться к калькулятору.
+func (c *Calculator) unlock() {
+	pending := c.pending
+	c.pending = nil
+	c.mu.Unlock()
+
+	for _, notify := range pending {
+		notify()
+	}
+}
+
+// snapshot копирует сессию для читателей. Завершенные бои больше не меняются,
+// поэтому копия разделяет их с оригиналом. Статистика копируется только в
+// записях, измененных после предыдущего снапшота prev; остальные записи
+// разделяются с ним.
+func (s *CombatSession) snapshot(prev *CombatSession) *CombatSession {
+	copied := *s
+	var prevCombat *Combat
+	if prev != nil {
+		copied.Breakdown = s.Breakdown.snapshot(&prev.Breakdown)
+		prevCombat = prev.CurrentCombat
+	} else {
+		copied.Breakdown = s.Breakdown.snapshot(nil)
+	}
+	// Бои только добавляются в конец, поэтому копия может разделять массив
+	copied.Encounters = s.Encounters[:len(s.Encounters):len(s.Encounters)]
+	copied.ActiveBuffs = maps.Clone(s.ActiveBuffs)
+	if s.CurrentCombat != nil && s.CurrentCombat.IsActive {
+		copied.CurrentCombat = s.CurrentCombat.snapshot(prevCombat)
+	}
+	return &copied
+}
+
+// snapshot копирует бой для снапшота сессии. prev - бой из предыдущего снапшота.
+func (c *Combat) snapshot(prev *Combat) *Combat {
+	if prev != nil && prev != c && prev.ID == c.ID {
+		return c.copyWith(c.Breakdown.snapshot(&prev.Breakdown))
+	}
+	return c.copyWith(c.Breakdown.snapshot(nil))
+}
+
+// clone копирует бой целиком, не трогая список изменений для снапшотов
+func (c *Combat) clone() *Combat {
+	return c.copyWith(c.Breakdown.clone())
+}
+
+// copyWith копирует бой с готовой копией статистики, баффами и таймлайном
+func (c *Combat) copyWith(breakdown Breakdown) *Combat {
+	copied := *c
+	copied.Breakdown = breakdown
+	copied.Timeline = append([]TimelineBucket(nil), c.Timeline...)
+	copied.Buffs = make(map[BuffKey]*BuffStats, len(c.Buffs))
+	for key, stats := range c.Buffs {
+		buff := *stats
+		buff.Intervals = append([]BuffInterval(nil), stats.Intervals...)
+		buff.AbilityDamage = maps.Clone(stats.AbilityDamage)
+		copied.Buffs[key] = &buff
+	}
+	return &copied
+}
+
+// changes хранит ключи записей Breakdown, измененных после последнего снапшота
+type changes struct {
+	published      bool // Снапшот уже снимался, изменения считаются от него
+	abilities      keySet
+	targets        keySet
+	takenBySource  keySet
+	takenByAbility keySet
+}
+
+// keySet множество ключей
+type keySet map[string]struct{}
+
+// add добавляет ключ, создавая множество при необходимости
+func (s *keySet) add(key string) {
+	if *s == nil {
+		*s = make(keySet)
+	}
+	(*s)[key] = struct{}{}
+}
+
+// snapshot копирует статистику и сбрасывает список изменений. prev -
+// предыдущий снапшот этой же статистики; nil или первый снапшот копирует
+// все записи.
+func (b *Breakdown) snapshot(prev *Breakdown) Breakdown {
+	if prev == nil || !b.changed.published {
+		copied := b.clone()
+		b.changed = changes{published: true}
+		return copied
+	}
+
+	copied := *b
+	copied.changed = changes{}
+	copied.Abilities = cloneAbilities(b.Abilities, prev.Abilities, b.changed.abilities)
+	copied.Targets = cloneValues(b.Targets, prev.Targets, b.changed.targets)
+	copied.TakenBySource = cloneValues(b.TakenBySource, prev.TakenBySource, b.changed.takenBySource)
+	copied.TakenByAbility = cloneAbilities(b.TakenByAbility, prev.TakenByAbility, b.changed.takenByAbility)
+	b.changed = changes{published: true}
+	return copied
+}
+
+// clone копирует статистику вместе со вложенными таблицами
+func (b Breakdown) clone() Breakdown {
+	b.changed = changes{}
+	b.Abilities = cloneAbilities(b.Abilities, nil, nil)
+	b.Targets = cloneValues(b.Targets, nil, nil)
+	b.TakenBySource = cloneValues(b.TakenBySource, nil, nil)
+	b.TakenByAbility = cloneAbilities(b.TakenByAbility, nil, nil)
+	return b
+}
+
+// cloneAbilities копирует статистику способностей вместе с распределением ударов
+func cloneAbilities(abilities, prev map[string]*AbilityStats, changed keySet) map[string]*AbilityStats {
+	return cloneEntries(abilities, prev, changed, func(ability *AbilityStats) *AbilityStats {
+		copied := *ability
+		copied.HitCounts = maps.Clone(ability.HitCounts)
+		return &copied
+	})
+}
+
+// cloneValues копирует таблицу указателей вместе со значениями
+func cloneValues[T any](values, prev map[string]*T, changed keySet) map[string]*T {
+	return cloneEntries(values, prev, changed, func(value *T) *T {
+		copied := *value
+		return &copied
+	})
+}
+
+// cloneEntries копирует таблицу. Если есть предыдущая копия prev, заново
+// копируются только записи из changed, остальные берутся из prev.
+func cloneEntries[T any](values, prev map[string]*T, changed keySet, clone func(*T) *T) map[string]*T {
+	if prev == nil {
+		copied := make(map[string]*T, len(values))
+		for key, value := range values {
+			copied[key] = clone(value)
+		}
+		return copied
+	}
+	if len(changed) == 0 {
+		return prev
+	}
+	copied := maps.Clone(prev)
+	for key := range changed {
+		copied[key] = clone(values[key])
+	}
+	return copied
+}
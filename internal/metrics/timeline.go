@@ -0,0 +1,63 @@
+package metrics
+
+import "time"
+
+// Окна для скользящих DPS/HPS
+const (
+	ShortWindow  = 5 * time.Second
+	MediumWindow = 15 * time.Second
+	LongWindow   = 30 * time.Second
+)
+
+// record добавляет значения в посекундную корзину боя
+func (c *Combat) record(at time.Time, damage, healing, taken int) {
+	bucket := c.bucketAt(at)
+	bucket.Damage += damage
+	bucket.Healing += healing
+	bucket.DamageTaken += taken
+}
+
+// bucketAt возвращает корзину для момента времени, дополняя ряд пустыми секундами
+func (c *Combat) bucketAt(at time.Time) *TimelineBucket {
+	second := secondOf(c.StartTime, at)
+	for len(c.Timeline) <= second {
+		c.Timeline = append(c.Timeline, TimelineBucket{Second: len(c.Timeline)})
+	}
+	return &c.Timeline[second]
+}
+
+// rate возвращает среднее значение в секунду за последнее окно до момента now.
+// Пока бой короче окна, делим на фактическую длительность.
+func (c *Combat) rate(now time.Time, window time.Duration, value func(TimelineBucket) int) float64 {
+	last := secondOf(c.StartTime, now)
+	first := last - int(window/time.Second) + 1
+	if first < 0 {
+		first = 0
+	}
+
+	sum := 0
+	for i := first; i <= last && i < len(c.Timeline); i++ {
+		sum += value(c.Timeline[i])
+	}
+
+	return float64(sum) / float64(last-first+1)
+}
+
+// windowFull сообщает, что к моменту now бой длится не меньше окна. Пока окно
+// неполное, rate делит на фактическую длительность, и один сильный первый
+// удар дает завышенное значение, поэтому максимумы считаются только по полным окнам.
+func (c *Combat) windowFull(now time.Time, window time.Duration) bool {
+	return secondOf(c.StartTime, now)+1 >= int(window/time.Second)
+}
+
+// secondOf возвращает номер секунды боя для момента времени
+func secondOf(start, at time.Time) int {
+	if at.Before(start) {
+		return 0
+	}
+	return int(at.Sub(start) / time.Second)
+}
+
+func bucketDamage(b TimelineBucket) int      { return b.Damage }
+func bucketHealing(b TimelineBucket) int     { return b.Healing }
+func bucketDamageTaken(b TimelineBucket) int { return b.DamageTaken }
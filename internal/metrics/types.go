@@ -1,6 +1,8 @@
 package metrics
 
-import "time"
+import (
+	"time"
+)
 
 // CombatStats представляет статистику боя
 type CombatStats struct {
@@ -16,11 +18,15 @@ type CombatStats struct {
 	TotalHealingHits int
 }
 
-// DPSStats представляет статистику DPS
+// DPSStats представляет статистику DPS. CurrentDPS - скользящее значение
+// за короткое окно, AvgDPS - среднее с начала боя.
 type DPSStats struct {
 	CurrentDPS  float64
 	MaxDPS      float64
 	AvgDPS      float64
+	Window5s    float64
+	Window15s   float64
+	Window30s   float64
 	TotalDamage int
 	Duration    time.Duration
 }
@@ -30,10 +36,33 @@ type HPSStats struct {
 	CurrentHPS   float64
 	MaxHPS       float64
 	AvgHPS       float64
+	Window5s     float64
+	Window15s    float64
+	Window30s    float64
 	TotalHealing int
 	Duration     time.Duration
 }
 
+// DamageTakenStats представляет статистику полученного урона
+type DamageTakenStats struct {
+	TotalDamage int
+	TotalHits   int
+	CritHits    int
+	CurrentDTPS float64
+	MaxDTPS     float64
+	AvgDTPS     float64
+	Duration    time.Duration
+}
+
+// SourceStats представляет статистику урона, полученного от источника
+type SourceStats struct {
+	Name    string
+	Damage  int
+	Hits    int
+	Crits   int
+	LastHit time.Time
+}
+
 // AbilityStats представляет статистику по способностям
 type AbilityStats struct {
 	Name        string
@@ -45,6 +74,10 @@ type AbilityStats struct {
 	CritHealing int
 	HealingHits int
 	LastUsed    time.Time
+	MinHit      int
+	MaxHit      int
+	CritDamage  int         // Урон от критических ударов
+	HitCounts   map[int]int // Число ударов каждого размера
 }
 
 // TargetStats представляет статистику по целям
@@ -57,13 +90,66 @@ type TargetStats struct {
 	Kills       int
 	CritHealing int
 	HealingHits int
+	FirstHit    time.Time
 	LastHit     time.Time
 }
 
-// CombatEvent представляет событие боя с временной меткой
-type CombatEvent struct {
-	Timestamp time.Time
-	Event     interface{}
+// BuffKey идентифицирует бафф на конкретной цели
+type BuffKey struct {
+	Name   string
+	Target string
+}
+
+// BuffInterval представляет интервал действия баффа; End пуст, пока бафф активен
+type BuffInterval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// BuffStats представляет статистику баффа или дебаффа за бой
+type BuffStats struct {
+	Name                  string
+	Target                string
+	IsDebuff              bool // true для эффектов, наложенных на других
+	Applications          int
+	Refreshes             int
+	CompletedApplications int
+	AppliedDuration       time.Duration // Суммарная длительность завершенных применений
+	Intervals             []BuffInterval
+	DamageDuring          int            // Урон, нанесенный пока эффект был активен
+	AbilityDamage         map[string]int // Тот же урон по способностям
+}
+
+// BuffAttribution представляет сравнение урона под баффом и без него
+type BuffAttribution struct {
+	ActiveTime     time.Duration
+	InactiveTime   time.Duration
+	DamageActive   int
+	DamageInactive int
+	DPSActive      float64
+	DPSInactive    float64
+	Gain           float64 // Прирост DPS под баффом в процентах
+}
+
+// Breakdown представляет накопленную статистику: общую, по способностям,
+// по целям и по полученному урону. Используется и для сессии, и для боя.
+type Breakdown struct {
+	Stats          CombatStats
+	Abilities      map[string]*AbilityStats
+	Targets        map[string]*TargetStats
+	DamageTaken    DamageTakenStats
+	TakenBySource  map[string]*SourceStats
+	TakenByAbility map[string]*AbilityStats
+
+	changed changes // Записи, измененные после последней публикации
+}
+
+// TimelineBucket представляет значения за одну секунду боя
+type TimelineBucket struct {
+	Second      int // Номер секунды от начала боя
+	Damage      int
+	Healing     int
+	DamageTaken int
 }
 
 // Combat представляет отдельный бой
@@ -72,23 +158,40 @@ type Combat struct {
 	StartTime    time.Time
 	EndTime      time.Time
 	IsActive     bool
-	TotalDamage  int
-	TotalHealing int
 	Duration     time.Duration
+	LastActivity time.Time
+	Timeline     []TimelineBucket
+	Buffs        map[BuffKey]*BuffStats
+	Breakdown
 }
 
 // CombatSession представляет сессию боя
 type CombatSession struct {
-	ID            string
-	StartTime     time.Time
-	EndTime       time.Time
-	IsActive      bool
-	Stats         CombatStats
+	ID        string
+	StartTime time.Time
+	EndTime   time.Time
+	IsActive  bool
+	Breakdown
 	DPSStats      DPSStats
 	HPSStats      HPSStats
-	Abilities     map[string]*AbilityStats
-	Targets       map[string]*TargetStats
-	RecentEvents  []CombatEvent
 	CurrentCombat *Combat
+	Encounters    []*Combat             // Завершенные бои в порядке начала
+	ActiveBuffs   map[BuffKey]time.Time // Активные баффы и время их наложения
 	LastActivity  time.Time
 }
+
+// Elapsed возвращает длительность сессии по времени событий лога
+func (s *CombatSession) Elapsed() time.Duration {
+	if s.StartTime.IsZero() {
+		return 0
+	}
+	return s.LastActivity.Sub(s.StartTime)
+}
+
+// Elapsed возвращает длительность боя; для активного боя - до последнего события
+func (c *Combat) Elapsed() time.Duration {
+	if c.IsActive {
+		return c.LastActivity.Sub(c.StartTime)
+	}
+	return c.Duration
+}
@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// BatchSize сколько событий передавать обработчику за раз
+const BatchSize = 1000
+
+// MaxLineSize максимальная длина строки лога. Более длинные строки не
+// бывают записями игры и пропускаются, не занимая память целиком.
+const MaxLineSize = 1 << 20
+
+// readBufferSize размер буфера чтения строк
+const readBufferSize = 64 << 10
+
+// LineReader читает строки лога из потока и передает разобранные события
+// пачками не больше BatchSize. Строки, которые не удалось разобрать, пропускаются.
+type LineReader struct {
+	Parser *Parser // nil - новый парсер
+
+	// Live передает неполную пачку, как только прочитанные данные
+	// закончились, чтобы живой поток не ждал заполнения пачки
+	Live bool
+
+	// KeepPartial оставляет непрочитанной строку без перевода строки в конце
+	// потока. Игра может сбросить на диск половину строки; следующее чтение
+	// файла прочитает ее целиком.
+	KeepPartial bool
+}
+
+// Read читает строки из r до конца потока и передает события в fn вместе с
+// числом байт, прочитанных к этому моменту. Если fn возвращает ошибку, чтение
+// прекращается. Возвращает число прочитанных байт: недописанная строка при
+// KeepPartial в него не входит.
+func (l LineReader) Read(r io.Reader, fn func(events []Event, read int64) error) (int64, error) {
+	p := l.Parser
+	if p == nil {
+		p = NewParser()
+	}
+
+	reader := bufio.NewReaderSize(r, readBufferSize)
+	var batch []Event
+	var line []byte
+	var read, pending int64 // pending - байты текущей строки
+	tooLong := false
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		events := batch
+		batch = nil
+		return fn(events, read)
+	}
+
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		pending += int64(len(chunk))
+		if !tooLong && len(line)+len(chunk) > MaxLineSize {
+			tooLong, line = true, line[:0]
+		}
+		if !tooLong {
+			line = append(line, chunk...)
+		}
+
+		// Строка не поместилась в буфер - дочитываем ее
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err != nil && err != io.EOF {
+			if flushErr := flush(); flushErr != nil {
+				return read, flushErr
+			}
+			return read, err
+		}
+		if err == io.EOF && (pending == 0 || l.KeepPartial) {
+			return read, flush()
+		}
+
+		read += pending
+		if text := strings.TrimRight(string(line), "\r\n"); !tooLong && text != "" {
+			if events, parseErr := p.ParseLine(text); parseErr == nil {
+				batch = append(batch, events...)
+			}
+		}
+		line, pending, tooLong = line[:0], 0, false
+
+		if len(batch) >= BatchSize || (l.Live && reader.Buffered() == 0) || err == io.EOF {
+			if flushErr := flush(); flushErr != nil {
+				return read, flushErr
+			}
+		}
+		if err == io.EOF {
+			return read, nil
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// combatLine возвращает строку лога с уроном amount
+func combatLine(amount int) string {
+	return fmt.Sprintf(`{"frame":1,"category":"LogAoC_CombatLog","verbosity":"Display","message":"[x][  0]LogAoC_CombatLog: Display: CombatLog: Player hit: %d damage dealt to Mob - Slash","timestamp":"2025-01-01T10:00:00.000Z"}`+"\n", amount)
+}
+
+// readAll читает поток и возвращает урон событий, размеры пачек и число прочитанных байт
+func readAll(t *testing.T, lines LineReader, r io.Reader) (amounts, batches []int, read int64) {
+	t.Helper()
+	read, err := lines.Read(r, func(events []Event, _ int64) error {
+		batches = append(batches, len(events))
+		for _, event := range events {
+			amounts = append(amounts, event.(*DamageEvent).Amount)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return amounts, batches, read
+}
+
+func TestLineReaderBatches(t *testing.T) {
+	var log strings.Builder
+	for i := 1; i <= 2*BatchSize+1; i++ {
+		log.WriteString(combatLine(i))
+	}
+
+	amounts, batches, read := readAll(t, LineReader{}, strings.NewReader(log.String()))
+	if len(amounts) != 2*BatchSize+1 || amounts[0] != 1 || amounts[len(amounts)-1] != 2*BatchSize+1 {
+		t.Fatalf("got %d events", len(amounts))
+	}
+	if len(batches) != 3 || batches[0] != BatchSize || batches[2] != 1 {
+		t.Errorf("batches = %v, want [%d %d 1]", batches, BatchSize, BatchSize)
+	}
+	if read != int64(log.Len()) {
+		t.Errorf("read = %d, want %d", read, log.Len())
+	}
+}
+
+func TestLineReaderPartialLine(t *testing.T) {
+	partial := combatLine(2)
+	partial = partial[:len(partial)/2]
+	log := combatLine(1) + partial
+
+	amounts, _, read := readAll(t, LineReader{KeepPartial: true}, strings.NewReader(log))
+	if len(amounts) != 1 || read != int64(len(combatLine(1))) {
+		t.Errorf("KeepPartial: amounts = %v, read = %d", amounts, read)
+	}
+
+	// Без KeepPartial последняя строка читается как есть
+	if _, _, read := readAll(t, LineReader{}, strings.NewReader(log)); read != int64(len(log)) {
+		t.Errorf("read = %d, want %d", read, len(log))
+	}
+}
+
+func TestLineReaderSkipsLongLines(t *testing.T) {
+	long := strings.Repeat("x", MaxLineSize+1) + "\n"
+	log := combatLine(1) + long + combatLine(2)
+
+	amounts, _, read := readAll(t, LineReader{}, strings.NewReader(log))
+	if len(amounts) != 2 || amounts[0] != 1 || amounts[1] != 2 {
+		t.Errorf("amounts = %v, want [1 2]", amounts)
+	}
+	if read != int64(len(log)) {
+		t.Errorf("read = %d, want %d", read, len(log))
+	}
+}
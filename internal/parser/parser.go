@@ -10,6 +10,24 @@ import (
 	"time"
 )
 
+// CombatCategory категория строк лога с событиями боя
+const CombatCategory = "LogAoC_CombatLog"
+
+// timestampLayout формат времени в логе
+const timestampLayout = "2006-01-02T15:04:05.000Z"
+
+// DecodeRecord разбирает JSON-строку лога, не разбирая само сообщение
+func DecodeRecord(line string) (CombatEvent, error) {
+	var event CombatEvent
+	err := json.Unmarshal([]byte(line), &event)
+	return event, err
+}
+
+// ParseTimestamp разбирает время из строки лога
+func ParseTimestamp(value string) (time.Time, error) {
+	return time.Parse(timestampLayout, value)
+}
+
 // Parser парсит логи Ashes of Creation
 type Parser struct {
 	// Регулярные выражения для парсинга событий
@@ -26,7 +44,8 @@ type Parser struct {
 func NewParser() *Parser {
 	return &Parser{
 		// Урон нанесенный: "83 damage(Crit) dealt to Wilderherd Berserker - Weapon_Wand_Projectile_1"
-		damageDealtRegex: regexp.MustCompile(`(\d+(?:,\d+)*) damage(\(Crit\))?(\(Lethal\))? dealt to (.+) - (.+)`),
+		// Суффикс убийства "[&Kill][KILL]Killed ..." не входит в название способности
+		damageDealtRegex: regexp.MustCompile(`(\d+(?:,\d+)*) damage(\(Crit\))?(\(Lethal\))? dealt to (.+) - (.+?)(?: \[&Kill\]\[KILL\]Killed .+)?$`),
 		// Урон полученный: "80 damage(Crit) received from Wilderherd Berserker - Axe Strike"
 		damageReceivedRegex: regexp.MustCompile(`(\d+(?:,\d+)*) damage(\(Crit\))?(\(Lethal\))? received from (.+) - (.+)`),
 		// Исцеление полученное: "103 healing(Crit) received from Your - Cleric_SoothingGlow"
@@ -42,40 +61,47 @@ func NewParser() *Parser {
 	}
 }
 
-// ParseLine парсит одну строку лога
-func (p *Parser) ParseLine(line string) (interface{}, error) {
+// ParseLine парсит одну строку лога. Одна строка может дать несколько
+// событий: смертельный удар порождает и урон, и убийство.
+func (p *Parser) ParseLine(line string) ([]Event, error) {
 	// Парсим JSON
-	var event CombatEvent
-	if err := json.Unmarshal([]byte(line), &event); err != nil {
+	event, err := DecodeRecord(line)
+	if err != nil {
 		return nil, err
 	}
 
 	// Проверяем, что это событие боя
-	if event.Category != "LogAoC_CombatLog" {
+	if event.Category != CombatCategory {
 		return nil, nil
 	}
 
 	// Парсим время
-	timestamp, err := time.Parse("2006-01-02T15:04:05.000Z", event.Timestamp)
+	timestamp, err := ParseTimestamp(event.Timestamp)
 	if err != nil {
 		return nil, err
 	}
 
 	// Парсим сообщение в зависимости от типа события
+	var events []Event
+
 	if damageEvent := p.parseDamageEvent(event, timestamp); damageEvent != nil {
-		return damageEvent, nil
+		events = append(events, damageEvent)
 	}
 
-	if healEvent := p.parseHealEvent(event, timestamp); healEvent != nil {
-		return healEvent, nil
+	if killEvent := p.parseKillEvent(event, timestamp); killEvent != nil {
+		events = append(events, killEvent)
 	}
 
-	if killEvent := p.parseKillEvent(event, timestamp); killEvent != nil {
-		return killEvent, nil
+	if len(events) > 0 {
+		return events, nil
+	}
+
+	if healEvent := p.parseHealEvent(event, timestamp); healEvent != nil {
+		return []Event{healEvent}, nil
 	}
 
 	if buffEvent := p.parseBuffEvent(event, timestamp); buffEvent != nil {
-		return buffEvent, nil
+		return []Event{buffEvent}, nil
 	}
 
 	return nil, nil
@@ -88,6 +114,7 @@ func (p *Parser) parseDamageEvent(event CombatEvent, timestamp time.Time) *Damag
 		amount, _ := strconv.Atoi(strings.ReplaceAll(matches[1], ",", ""))
 		return &DamageEvent{
 			Timestamp: timestamp,
+			Message:   event.Message,
 			Amount:    amount,
 			IsCrit:    matches[2] == "(Crit)",
 			IsLethal:  matches[3] == "(Lethal)",
@@ -103,6 +130,7 @@ func (p *Parser) parseDamageEvent(event CombatEvent, timestamp time.Time) *Damag
 		amount, _ := strconv.Atoi(strings.ReplaceAll(matches[1], ",", ""))
 		return &DamageEvent{
 			Timestamp: timestamp,
+			Message:   event.Message,
 			Amount:    amount,
 			IsCrit:    matches[2] == "(Crit)",
 			IsLethal:  matches[3] == "(Lethal)",
@@ -123,6 +151,7 @@ func (p *Parser) parseHealEvent(event CombatEvent, timestamp time.Time) *HealEve
 		amount, _ := strconv.Atoi(strings.ReplaceAll(matches[1], ",", ""))
 		return &HealEvent{
 			Timestamp: timestamp,
+			Message:   event.Message,
 			Amount:    amount,
 			IsCrit:    matches[2] == "(Crit)",
 			Target:    "You", // Предполагаем, что игрок получает исцеление
@@ -141,6 +170,7 @@ func (p *Parser) parseKillEvent(event CombatEvent, timestamp time.Time) *KillEve
 		amount, _ := strconv.Atoi(strings.ReplaceAll(matches[1], ",", ""))
 		return &KillEvent{
 			Timestamp: timestamp,
+			Message:   event.Message,
 			Target:    matches[4],
 			Source:    "You", // Предполагаем, что игрок убивает
 			Ability:   matches[5],
@@ -158,6 +188,7 @@ func (p *Parser) parseBuffEvent(event CombatEvent, timestamp time.Time) *BuffEve
 	if matches := p.buffReceivedRegex.FindStringSubmatch(event.Message); matches != nil {
 		return &BuffEvent{
 			Timestamp: timestamp,
+			Message:   event.Message,
 			Type:      "Received",
 			BuffName:  matches[1],
 			Target:    "You", // Предполагаем, что игрок получает бафф
@@ -169,6 +200,7 @@ func (p *Parser) parseBuffEvent(event CombatEvent, timestamp time.Time) *BuffEve
 	if matches := p.buffAppliedRegex.FindStringSubmatch(event.Message); matches != nil {
 		return &BuffEvent{
 			Timestamp: timestamp,
+			Message:   event.Message,
 			Type:      "Applied",
 			BuffName:  matches[1],
 			Target:    matches[2],
@@ -180,6 +212,7 @@ func (p *Parser) parseBuffEvent(event CombatEvent, timestamp time.Time) *BuffEve
 	if matches := p.buffRemovedRegex.FindStringSubmatch(event.Message); matches != nil {
 		return &BuffEvent{
 			Timestamp: timestamp,
+			Message:   event.Message,
 			Type:      "Removed",
 			BuffName:  matches[1],
 			Target:    matches[2],
@@ -191,24 +224,19 @@ func (p *Parser) parseBuffEvent(event CombatEvent, timestamp time.Time) *BuffEve
 }
 
 // ParseFile парсит весь файл лога
-func (p *Parser) ParseFile(filename string) ([]interface{}, error) {
+func (p *Parser) ParseFile(filename string) ([]Event, error) {
 	file, err := os.Open(filename)
 	if err != nil {
 		return nil, err
 	}
 	defer file.Close()
 
-	var events []interface{}
-	scanner := bufio.NewScanner(file)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		if event, err := p.ParseLine(line); err == nil && event != nil {
-			events = append(events, event)
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
+	var events []Event
+	lines := LineReader{Parser: p}
+	if _, err := lines.Read(file, func(batch []Event, read int64) error {
+		events = append(events, batch...)
+		return nil
+	}); err != nil {
 		return nil, err
 	}
 
@@ -216,14 +244,14 @@ func (p *Parser) ParseFile(filename string) ([]interface{}, error) {
 }
 
 // ParseFileFromLine парсит файл начиная с определенной строки
-func (p *Parser) ParseFileFromLine(filename string, startLine int) ([]interface{}, error) {
+func (p *Parser) ParseFileFromLine(filename string, startLine int) ([]Event, error) {
 	file, err := os.Open(filename)
 	if err != nil {
 		return nil, err
 	}
 	defer file.Close()
 
-	var events []interface{}
+	var events []Event
 	scanner := bufio.NewScanner(file)
 	currentLine := 0
 
@@ -234,8 +262,8 @@ func (p *Parser) ParseFileFromLine(filename string, startLine int) ([]interface{
 		}
 
 		line := scanner.Text()
-		if event, err := p.ParseLine(line); err == nil && event != nil {
-			events = append(events, event)
+		if parsed, err := p.ParseLine(line); err == nil {
+			events = append(events, parsed...)
 		}
 	}
 
@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// logLine оборачивает сообщение боевого лога в строку JSON
+func logLine(message string) string {
+	return fmt.Sprintf(`{"frame":1,"category":"LogAoC_CombatLog","verbosity":"Display","message":"[x][  0]LogAoC_CombatLog: Display: CombatLog: %s","timestamp":"2025-01-01T10:00:00.000Z"}`, message)
+}
+
+func TestParseLine(t *testing.T) {
+	at := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		message string
+		want    []Event
+	}{
+		{
+			name:    "damage dealt",
+			message: "Player hit: 1,083 damage(Crit) dealt to Wilderherd Berserker - Weapon_Wand_Projectile_1",
+			want: []Event{&DamageEvent{
+				Amount: 1083, IsCrit: true, Target: "Wilderherd Berserker", Source: "You",
+				Ability: "Weapon_Wand_Projectile_1", IsDealt: true,
+			}},
+		},
+		{
+			name:    "killing blow",
+			message: "Player hit: 95 damage(Crit)(Lethal) dealt to Wilderherd Berserker - Weapon_Wand_Projectile_1 [&Kill][KILL]Killed Wilderherd Berserker",
+			want: []Event{
+				&DamageEvent{
+					Amount: 95, IsCrit: true, IsLethal: true, Target: "Wilderherd Berserker", Source: "You",
+					Ability: "Weapon_Wand_Projectile_1", IsDealt: true,
+				},
+				&KillEvent{
+					Target: "Wilderherd Berserker", Source: "You", Ability: "Weapon_Wand_Projectile_1",
+					Damage: 95, IsCrit: true,
+				},
+			},
+		},
+		{
+			name:    "damage received",
+			message: "Player hit: 80 damage received from Wilderherd Berserker - Axe Strike",
+			want: []Event{&DamageEvent{
+				Amount: 80, Target: "You", Source: "Wilderherd Berserker", Ability: "Axe Strike",
+			}},
+		},
+		{
+			name:    "healing",
+			message: "Player hit: 103 healing(Crit) received from Your - Cleric_SoothingGlow",
+			want: []Event{&HealEvent{
+				Amount: 103, IsCrit: true, Target: "You", Source: "Your", Ability: "Cleric_SoothingGlow",
+			}},
+		},
+		{
+			name:    "unknown message",
+			message: "Player entered combat",
+		},
+	}
+
+	p := NewParser()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := p.ParseLine(logLine(tt.message))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Время и исходное сообщение одинаковы у всех событий строки
+			message := "[x][  0]LogAoC_CombatLog: Display: CombatLog: " + tt.message
+			for _, event := range tt.want {
+				switch e := event.(type) {
+				case *DamageEvent:
+					e.Timestamp, e.Message = at, message
+				case *KillEvent:
+					e.Timestamp, e.Message = at, message
+				case *HealEvent:
+					e.Timestamp, e.Message = at, message
+				}
+			}
+			if !reflect.DeepEqual(events, tt.want) {
+				t.Errorf("ParseLine() =\n%s\nwant\n%s", describe(events), describe(tt.want))
+			}
+		})
+	}
+}
+
+// describe выводит события с содержимым указателей
+func describe(events []Event) string {
+	text := ""
+	for _, event := range events {
+		text += fmt.Sprintf("%+v\n", event)
+	}
+	return text
+}
@@ -0,0 +1,44 @@
+package parser
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+)
+
+// sniffLimit сколько байт от начала файла просматривать в поисках боевых строк
+const sniffLimit = 16 << 20
+
+// IsCombatLog проверяет, что файл похож на лог игры: среди первых строк
+// есть хотя бы одна запись категории LogAoC_CombatLog
+func IsCombatLog(path string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	return HasCombatRecords(io.LimitReader(file, sniffLimit))
+}
+
+// HasCombatRecords читает строки до конца r и сообщает, встретилась ли
+// запись категории LogAoC_CombatLog
+func HasCombatRecords(r io.Reader) (bool, error) {
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if strings.Contains(line, CombatCategory) {
+			if record, decodeErr := DecodeRecord(strings.TrimRight(line, "\r\n")); decodeErr == nil && record.Category == CombatCategory {
+				return true, nil
+			}
+		}
+
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+}
@@ -10,9 +10,50 @@ type CombatEvent struct {
 	Message   string `json:"message"`
 }
 
+// EventKind определяет тип события боя
+type EventKind string
+
+const (
+	KindDamage      EventKind = "damage"
+	KindHeal        EventKind = "heal"
+	KindKill        EventKind = "kill"
+	KindBuff        EventKind = "buff"
+	KindCombatState EventKind = "combat_state"
+)
+
+// Event общий интерфейс для всех событий, которые производит парсер.
+// Интерфейс закрыт: реализовать его могут только типы из этого пакета.
+type Event interface {
+	// Time возвращает время события из лога
+	Time() time.Time
+	// Kind возвращает тип события
+	Kind() EventKind
+	// Raw возвращает исходное сообщение из лога
+	Raw() string
+	// dispatch вызывает подходящий метод Handler
+	dispatch(h Handler)
+}
+
+// Handler обрабатывает события каждого типа. При добавлении нового типа
+// события сюда добавляется метод, и компилятор укажет на все обработчики,
+// которые его не реализуют.
+type Handler interface {
+	HandleDamage(e *DamageEvent)
+	HandleHeal(e *HealEvent)
+	HandleKill(e *KillEvent)
+	HandleBuff(e *BuffEvent)
+	HandleCombatState(e *CombatStateEvent)
+}
+
+// Dispatch передает событие в соответствующий метод обработчика
+func Dispatch(e Event, h Handler) {
+	e.dispatch(h)
+}
+
 // DamageEvent представляет событие урона
 type DamageEvent struct {
 	Timestamp time.Time
+	Message   string
 	Amount    int
 	IsCrit    bool
 	IsLethal  bool
@@ -25,6 +66,7 @@ type DamageEvent struct {
 // HealEvent представляет событие исцеления
 type HealEvent struct {
 	Timestamp time.Time
+	Message   string
 	Amount    int
 	IsCrit    bool
 	Target    string
@@ -36,6 +78,7 @@ type HealEvent struct {
 // KillEvent представляет событие убийства
 type KillEvent struct {
 	Timestamp time.Time
+	Message   string
 	Target    string
 	Source    string
 	Ability   string
@@ -46,6 +89,7 @@ type KillEvent struct {
 // BuffEvent представляет событие баффа/дебаффа
 type BuffEvent struct {
 	Timestamp time.Time
+	Message   string
 	Type      string // "Received", "Applied", "Removed"
 	BuffName  string
 	Target    string
@@ -55,7 +99,33 @@ type BuffEvent struct {
 // CombatStateEvent представляет событие изменения состояния боя
 type CombatStateEvent struct {
 	Timestamp time.Time
+	Message   string
 	State     string // "Entered", "Exited", "Started", "Ended"
 	Target    string
 	Source    string
 }
+
+func (e *DamageEvent) Time() time.Time    { return e.Timestamp }
+func (e *DamageEvent) Kind() EventKind    { return KindDamage }
+func (e *DamageEvent) Raw() string        { return e.Message }
+func (e *DamageEvent) dispatch(h Handler) { h.HandleDamage(e) }
+
+func (e *HealEvent) Time() time.Time    { return e.Timestamp }
+func (e *HealEvent) Kind() EventKind    { return KindHeal }
+func (e *HealEvent) Raw() string        { return e.Message }
+func (e *HealEvent) dispatch(h Handler) { h.HandleHeal(e) }
+
+func (e *KillEvent) Time() time.Time    { return e.Timestamp }
+func (e *KillEvent) Kind() EventKind    { return KindKill }
+func (e *KillEvent) Raw() string        { return e.Message }
+func (e *KillEvent) dispatch(h Handler) { h.HandleKill(e) }
+
+func (e *BuffEvent) Time() time.Time    { return e.Timestamp }
+func (e *BuffEvent) Kind() EventKind    { return KindBuff }
+func (e *BuffEvent) Raw() string        { return e.Message }
+func (e *BuffEvent) dispatch(h Handler) { h.HandleBuff(e) }
+
+func (e *CombatStateEvent) Time() time.Time    { return e.Timestamp }
+func (e *CombatStateEvent) Kind() EventKind    { return KindCombatState }
+func (e *CombatStateEvent) Raw() string        { return e.Message }
+func (e *CombatStateEvent) dispatch(h Handler) { h.HandleCombatState(e) }
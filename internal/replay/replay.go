@@ -0,0 +1,366 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"aocdpsmetr/internal/parser"
+)
+
+// Speed скорость воспроизведения: во сколько раз время лога идет быстрее реального
+type Speed float64
+
+const (
+	SpeedRealtime Speed = 1
+	Speed4x       Speed = 4
+	Speed16x      Speed = 16
+	SpeedInstant  Speed = 0 // Без пауз между событиями
+)
+
+// ParseSpeed переводит название скорости ("1x", "4x", "16x", "instant") в Speed
+func ParseSpeed(value string) (Speed, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "1x":
+		return SpeedRealtime, nil
+	case "4x":
+		return Speed4x, nil
+	case "16x":
+		return Speed16x, nil
+	case "instant":
+		return SpeedInstant, nil
+	default:
+		return 0, fmt.Errorf("unknown replay speed %q", value)
+	}
+}
+
+// String возвращает название скорости в формате ParseSpeed
+func (s Speed) String() string {
+	if s == SpeedInstant {
+		return "instant"
+	}
+	return fmt.Sprintf("%gx", float64(s))
+}
+
+// Player воспроизводит записанный лог: отдает события в callback с теми же
+// паузами между ними, что были в игре, с учетом скорости. Callback тот же,
+// что у watcher.Watcher, поэтому калькулятор и интерфейс не отличают
+// воспроизведение от живого лога. Все вызовы callback и обработчиков
+// происходят в одной горутине воспроизведения.
+type Player struct {
+	filename string
+	callback func([]parser.Event)
+	onSeek   func(at time.Time)
+	onFinish func()
+	now      func() time.Time // Реальное время
+
+	mu       sync.Mutex
+	events   []parser.Event
+	next     int // Индекс следующего события
+	speed    Speed
+	paused   bool
+	logTime  time.Time // Время лога в момент wallTime
+	wallTime time.Time
+	seek     *time.Time // Перемотка, которую еще не обработала горутина воспроизведения
+	finished bool
+	started  bool
+
+	wake   chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewPlayer создает воспроизведение файла лога
+func NewPlayer(filename string, callback func([]parser.Event)) *Player {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Player{
+		filename: filename,
+		callback: callback,
+		now:      time.Now,
+		speed:    SpeedRealtime,
+		wake:     make(chan struct{}, 1),
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+}
+
+// OnSeek задает обработчик перемотки. Он вызывается перед событиями с новой
+// позиции; события до нее пропускаются, поэтому статистику стоит сбросить.
+func (p *Player) OnSeek(fn func(at time.Time)) {
+	p.onSeek = fn
+}
+
+// OnFinish задает обработчик, который вызывается, когда события закончились
+func (p *Player) OnFinish(fn func()) {
+	p.onFinish = fn
+}
+
+// Start начинает воспроизведение с первого события. Файл разбирается в
+// горутине воспроизведения, поэтому Start не ждет чтения большого лога.
+func (p *Player) Start() error {
+	file, err := os.Open(p.filename)
+	if err != nil {
+		return fmt.Errorf("failed to read log: %w", err)
+	}
+
+	p.mu.Lock()
+	p.started = true
+	p.mu.Unlock()
+
+	go p.loop(file)
+	return nil
+}
+
+// Stop останавливает воспроизведение и ждет, пока callback перестанет вызываться
+func (p *Player) Stop() {
+	p.cancel()
+	p.mu.Lock()
+	started := p.started
+	p.mu.Unlock()
+	if started {
+		<-p.done
+	}
+}
+
+// load читает события файла пачками и ставит время воспроизведения на первое событие
+func (p *Player) load(file io.ReadCloser) error {
+	defer file.Close()
+
+	var events []parser.Event
+	_, err := parser.LineReader{}.Read(file, func(batch []parser.Event, _ int64) error {
+		events = append(events, batch...)
+		return p.ctx.Err()
+	})
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.events = events
+	if len(events) > 0 {
+		p.logTime = events[0].Time()
+		p.wallTime = p.now()
+	}
+	p.mu.Unlock()
+
+	if len(events) == 0 {
+		fmt.Printf("No combat events in %s\n", p.filename)
+	} else {
+		fmt.Printf("Replaying %d events from %s\n", len(events), p.filename)
+	}
+	return nil
+}
+
+// SetSpeed меняет скорость воспроизведения на ходу
+func (p *Player) SetSpeed(speed Speed) {
+	p.mu.Lock()
+	p.rebaseLocked()
+	p.speed = speed
+	p.mu.Unlock()
+	p.notify()
+}
+
+// Pause останавливает время воспроизведения
+func (p *Player) Pause() {
+	p.mu.Lock()
+	p.rebaseLocked()
+	p.paused = true
+	p.mu.Unlock()
+	p.notify()
+}
+
+// Resume продолжает воспроизведение после паузы
+func (p *Player) Resume() {
+	p.mu.Lock()
+	p.rebaseLocked()
+	p.paused = false
+	p.mu.Unlock()
+	p.notify()
+}
+
+// Seek переходит к первому событию не раньше at. Перемотка возможна и назад,
+// и после окончания воспроизведения.
+func (p *Player) Seek(at time.Time) {
+	p.mu.Lock()
+	p.seek = &at
+	p.mu.Unlock()
+	p.notify()
+}
+
+// Clock возвращает текущее время воспроизведения. Его можно передать в
+// metrics.Calculator.SetClock, чтобы бои завершались по времени лога. После
+// последнего события время продолжает идти, в мгновенном режиме - стоит.
+func (p *Player) Clock() time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.clockLocked()
+}
+
+// State снимок состояния воспроизведения
+type State struct {
+	Speed    Speed
+	Paused   bool
+	Finished bool
+	Position time.Time // Текущее время воспроизведения
+	Start    time.Time // Время первого события
+	End      time.Time // Время последнего события
+}
+
+// State возвращает текущее состояние воспроизведения
+func (p *Player) State() State {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	state := State{
+		Speed:    p.speed,
+		Paused:   p.paused,
+		Finished: p.finished,
+		Position: p.clockLocked(),
+	}
+	if len(p.events) > 0 {
+		state.Start = p.events[0].Time()
+		state.End = p.events[len(p.events)-1].Time()
+	}
+	return state
+}
+
+// loop читает файл и отдает события, когда наступает их время
+func (p *Player) loop(file io.ReadCloser) {
+	defer close(p.done)
+
+	if err := p.load(file); err != nil {
+		if p.ctx.Err() != nil {
+			return
+		}
+		fmt.Println("Failed to read log:", err)
+	}
+
+	for {
+		if p.ctx.Err() != nil {
+			return
+		}
+
+		p.mu.Lock()
+		if at := p.seek; at != nil {
+			p.seek = nil
+			p.seekLocked(*at)
+			p.mu.Unlock()
+			if p.onSeek != nil {
+				p.onSeek(*at)
+			}
+			continue
+		}
+
+		if p.paused || p.next >= len(p.events) {
+			finished := !p.finished && p.next >= len(p.events)
+			p.finished = p.next >= len(p.events)
+			p.mu.Unlock()
+			if finished && p.onFinish != nil {
+				fmt.Println("Replay finished")
+				p.onFinish()
+			}
+			if !p.sleep(nil) {
+				return
+			}
+			continue
+		}
+
+		batch, wait := p.dueLocked()
+		p.mu.Unlock()
+
+		if len(batch) > 0 {
+			p.callback(batch)
+			continue
+		}
+
+		timer := time.NewTimer(wait)
+		ok := p.sleep(timer.C)
+		timer.Stop()
+		if !ok {
+			return
+		}
+	}
+}
+
+// dueLocked забирает события, время которых уже наступило, или возвращает,
+// сколько ждать до следующего события
+func (p *Player) dueLocked() ([]parser.Event, time.Duration) {
+	if p.speed == SpeedInstant {
+		end := p.next + parser.BatchSize
+		if end > len(p.events) {
+			end = len(p.events)
+		}
+		batch := p.events[p.next:end]
+		p.next = end
+		// Время воспроизведения встает на последнее отданное событие
+		p.logTime = batch[len(batch)-1].Time()
+		p.wallTime = p.now()
+		return batch, 0
+	}
+
+	now := p.clockLocked()
+	start := p.next
+	for p.next < len(p.events) && p.next-start < parser.BatchSize && !p.events[p.next].Time().After(now) {
+		p.next++
+	}
+	if p.next > start {
+		return p.events[start:p.next], 0
+	}
+
+	gap := p.events[p.next].Time().Sub(now)
+	return nil, time.Duration(float64(gap) / float64(p.speed))
+}
+
+// seekLocked ставит позицию на первое событие не раньше at. События лога
+// идут по времени, поэтому используется двоичный поиск.
+func (p *Player) seekLocked(at time.Time) {
+	p.next = sort.Search(len(p.events), func(i int) bool {
+		return !p.events[i].Time().Before(at)
+	})
+	p.finished = false
+	p.logTime = at
+	p.wallTime = p.now()
+	fmt.Println("Replay position:", at.Format(time.RFC3339))
+}
+
+// clockLocked вычисляет время лога по реальному времени с учетом скорости и паузы
+func (p *Player) clockLocked() time.Time {
+	if p.paused || p.speed == SpeedInstant {
+		return p.logTime
+	}
+	elapsed := p.now().Sub(p.wallTime)
+	return p.logTime.Add(time.Duration(float64(elapsed) * float64(p.speed)))
+}
+
+// rebaseLocked фиксирует текущее время воспроизведения перед сменой скорости или паузой
+func (p *Player) rebaseLocked() {
+	p.logTime = p.clockLocked()
+	p.wallTime = p.now()
+}
+
+// notify будит горутину воспроизведения после изменения состояния
+func (p *Player) notify() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// sleep ждет таймера, изменения состояния или остановки. Возвращает false при остановке.
+func (p *Player) sleep(timer <-chan time.Time) bool {
+	select {
+	case <-p.ctx.Done():
+		return false
+	case <-p.wake:
+	case <-timer:
+	}
+	return true
+}
@@ -0,0 +1,182 @@
+package replay
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"aocdpsmetr/internal/parser"
+)
+
+var testStart = time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+// fakeClock реальное время, которое тест двигает вручную
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time          { return c.now }
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// newTestPlayer создает воспроизведение n событий с интервалом в секунду без чтения файла
+func newTestPlayer(n int, speed Speed) (*Player, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+	p := NewPlayer("", nil)
+	p.now = clock.Now
+	for i := 0; i < n; i++ {
+		p.events = append(p.events, &parser.DamageEvent{Timestamp: testStart.Add(time.Duration(i) * time.Second), Amount: i + 1})
+	}
+	p.speed = speed
+	p.logTime = testStart
+	p.wallTime = clock.now
+	return p, clock
+}
+
+func TestDueFollowsSpeed(t *testing.T) {
+	for _, speed := range []Speed{SpeedRealtime, Speed4x, Speed16x} {
+		t.Run(speed.String(), func(t *testing.T) {
+			p, clock := newTestPlayer(3, speed)
+
+			for i := 0; i < 3; i++ {
+				batch, wait := p.dueLocked()
+				if len(batch) != 1 || batch[0].(*parser.DamageEvent).Amount != i+1 {
+					t.Fatalf("step %d: batch = %v", i, batch)
+				}
+				if wait != 0 {
+					t.Errorf("step %d: wait = %v with a due event", i, wait)
+				}
+				if i == 2 {
+					break
+				}
+
+				// Секунда лога проходит за 1/speed реального времени
+				batch, wait = p.dueLocked()
+				if want := time.Duration(float64(time.Second) / float64(speed)); len(batch) != 0 || wait != want {
+					t.Fatalf("step %d: got %d events, wait %v, want %v", i, len(batch), wait, want)
+				}
+				clock.Advance(wait)
+			}
+		})
+	}
+}
+
+func TestDueInstantTakesBatch(t *testing.T) {
+	p, _ := newTestPlayer(parser.BatchSize+1, SpeedInstant)
+
+	batch, wait := p.dueLocked()
+	if len(batch) != parser.BatchSize || wait != 0 {
+		t.Fatalf("got %d events, wait %v", len(batch), wait)
+	}
+	if want := batch[len(batch)-1].Time(); !p.Clock().Equal(want) {
+		t.Errorf("Clock() = %v, want last event %v", p.Clock(), want)
+	}
+	if batch, _ := p.dueLocked(); len(batch) != 1 {
+		t.Errorf("got %d events in the last batch, want 1", len(batch))
+	}
+}
+
+func TestSpeedChangeKeepsPosition(t *testing.T) {
+	p, clock := newTestPlayer(100, Speed4x)
+
+	clock.Advance(time.Second)
+	if got, want := p.Clock(), testStart.Add(4*time.Second); !got.Equal(want) {
+		t.Fatalf("Clock() = %v, want %v", got, want)
+	}
+
+	// Смена скорости не сдвигает позицию, дальше время идет с новой скоростью
+	p.SetSpeed(Speed16x)
+	if got, want := p.Clock(), testStart.Add(4*time.Second); !got.Equal(want) {
+		t.Errorf("after SetSpeed: Clock() = %v, want %v", got, want)
+	}
+	clock.Advance(time.Second)
+	if got, want := p.Clock(), testStart.Add(20*time.Second); !got.Equal(want) {
+		t.Errorf("Clock() = %v, want %v", got, want)
+	}
+
+	p.Pause()
+	clock.Advance(time.Minute)
+	if got, want := p.Clock(), testStart.Add(20*time.Second); !got.Equal(want) {
+		t.Errorf("paused: Clock() = %v, want %v", got, want)
+	}
+	p.Resume()
+	clock.Advance(time.Second)
+	if got, want := p.Clock(), testStart.Add(36*time.Second); !got.Equal(want) {
+		t.Errorf("resumed: Clock() = %v, want %v", got, want)
+	}
+}
+
+// writeLog создает лог из n строк урона 1..n с интервалом в секунду
+func writeLog(t *testing.T, n int) string {
+	t.Helper()
+	var log strings.Builder
+	for i := 0; i < n; i++ {
+		timestamp := testStart.Add(time.Duration(i) * time.Second).Format("2006-01-02T15:04:05.000Z")
+		fmt.Fprintf(&log, `{"frame":%d,"category":"LogAoC_CombatLog","verbosity":"Display","message":"[x][  0]LogAoC_CombatLog: Display: CombatLog: Player hit: %d damage dealt to Mob - Slash","timestamp":"%s"}`+"\n", i+1, i+1, timestamp)
+	}
+
+	path := filepath.Join(t.TempDir(), "AOC.log")
+	if err := os.WriteFile(path, []byte(log.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSeekReplaysFromPosition(t *testing.T) {
+	// Обработчики вызываются в горутине воспроизведения, поэтому пишут в канал
+	calls := make(chan string, 100)
+	p := NewPlayer(writeLog(t, 5), func(events []parser.Event) {
+		amounts := make([]string, len(events))
+		for i, event := range events {
+			amounts[i] = fmt.Sprint(event.(*parser.DamageEvent).Amount)
+		}
+		calls <- "events " + strings.Join(amounts, ",")
+	})
+	p.OnSeek(func(at time.Time) { calls <- "seek " + at.Sub(testStart).String() })
+	p.OnFinish(func() { calls <- "finish" })
+	p.SetSpeed(SpeedInstant)
+
+	expect := func(want ...string) {
+		t.Helper()
+		for _, w := range want {
+			select {
+			case got := <-calls:
+				if got != w {
+					t.Fatalf("got %q, want %q", got, w)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timed out waiting for %q", w)
+			}
+		}
+	}
+
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+	expect("events 1,2,3,4,5", "finish")
+
+	// Пробуждения после окончания не вызывают OnFinish повторно
+	p.Resume()
+	p.SetSpeed(SpeedInstant)
+
+	// Назад после окончания: события отдаются с первого не раньше позиции
+	p.Seek(testStart.Add(2500 * time.Millisecond))
+	expect("seek 2.5s", "events 4,5", "finish")
+
+	p.Seek(testStart.Add(time.Second))
+	expect("seek 1s", "events 2,3,4,5", "finish")
+
+	// После последнего события отдавать нечего
+	p.Seek(testStart.Add(time.Hour))
+	expect("seek 1h0m0s", "finish")
+
+	select {
+	case got := <-calls:
+		t.Errorf("unexpected call %q", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if state := p.State(); !state.Finished || !state.End.Equal(testStart.Add(4*time.Second)) {
+		t.Errorf("State() = %+v", state)
+	}
+}
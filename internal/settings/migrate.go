@@ -0,0 +1,51 @@
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// CurrentVersion версия схемы файла настроек. При изменении схемы версия
+// увеличивается, а в migrations добавляется шаг с предыдущей версии.
+const CurrentVersion = 1
+
+// migration переводит файл настроек с версии N на N+1
+type migration func(raw map[string]json.RawMessage) error
+
+// migrations[N] переводит файл с версии N на N+1
+var migrations = []migration{
+	// 0 -> 1: файлы, сохраненные до появления поля version. Их разделы
+	// совпадают с версией 1, а новый раздел logs заполнится
+	// значениями по умолчанию при чтении.
+	func(raw map[string]json.RawMessage) error { return nil },
+}
+
+// migrate приводит содержимое файла настроек к CurrentVersion
+func migrate(data []byte) ([]byte, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse settings: %w", err)
+	}
+
+	version := 0
+	if value, exists := raw["version"]; exists {
+		if err := json.Unmarshal(value, &version); err != nil {
+			return nil, fmt.Errorf("invalid settings version: %w", err)
+		}
+	}
+	if version > CurrentVersion {
+		return nil, fmt.Errorf("settings version %d is newer than supported %d", version, CurrentVersion)
+	}
+	if version == CurrentVersion {
+		return data, nil
+	}
+
+	for ; version < CurrentVersion; version++ {
+		if err := migrations[version](raw); err != nil {
+			return nil, fmt.Errorf("failed to migrate settings from version %d: %w", version, err)
+		}
+	}
+
+	raw["version"] = json.RawMessage(fmt.Sprint(CurrentVersion))
+	return json.Marshal(raw)
+}
@@ -0,0 +1,205 @@
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"aocdpsmetr/internal/metrics"
+	"aocdpsmetr/internal/watcher"
+)
+
+// appDirName имя каталога приложения внутри пользовательского каталога настроек
+const appDirName = "aocdpsmetr"
+
+// fileName имя файла настроек
+const fileName = "settings.json"
+
+// Settings пользовательские настройки приложения
+type Settings struct {
+	Version      int          `json:"version"` // Версия схемы файла, см. CurrentVersion
+	Logs         Logs         `json:"logs"`
+	Segmentation Segmentation `json:"segmentation"`
+	Monitoring   Monitoring   `json:"monitoring"`
+	Updates      Updates      `json:"updates"`
+}
+
+// Logs настройки поиска и чтения файла лога
+type Logs struct {
+	ChosenPath     string   `json:"chosenPath"`     // Файл, выбранный пользователем; проверяется первым
+	SearchPaths    []string `json:"searchPaths"`    // Пути к AOC.log по порядку проверки; $VAR заменяется переменной окружения
+	PollIntervalMs int      `json:"pollIntervalMs"` // Как часто проверять файл на случай пропущенных событий
+}
+
+// Segmentation настройки разбиения лога на бои
+type Segmentation struct {
+	Mode               string `json:"mode"`
+	IdleTimeoutSeconds int    `json:"idleTimeoutSeconds"`
+}
+
+// Monitoring настройки запуска мониторинга
+type Monitoring struct {
+	StartMode string `json:"startMode"` // "end", "beginning", "timestamp" или "session"
+	StartTime string `json:"startTime"` // RFC3339, для режима "timestamp"
+}
+
+// Updates настройки отправки обновлений во фронтенд
+type Updates struct {
+	MaxPerSecond int `json:"maxPerSecond"` // Не чаще стольких обновлений статистики в секунду
+}
+
+// Допустимые диапазоны значений
+const (
+	minPollIntervalMs   = 10
+	maxPollIntervalMs   = 10000
+	maxIdleTimeout      = 600
+	minUpdatesPerSecond = 1
+	maxUpdatesPerSecond = 60
+)
+
+// Default возвращает настройки по умолчанию
+func Default() *Settings {
+	return &Settings{
+		Version: CurrentVersion,
+		Logs: Logs{
+			SearchPaths: []string{
+				// Стандартный путь для Windows
+				"$USERPROFILE/AppData/Local/AOC/Saved/Logs/AOC.log",
+				// Альтернативные пути
+				"$USERPROFILE/Documents/AOC/Logs/AOC.log",
+				"$USERPROFILE/AppData/Roaming/AOC/Logs/AOC.log",
+				// Локальный файл для тестирования
+				"AOC.log",
+			},
+			PollIntervalMs: int(watcher.DefaultPollInterval / time.Millisecond),
+		},
+		Segmentation: Segmentation{
+			Mode:               string(metrics.SegmentIdle),
+			IdleTimeoutSeconds: int(metrics.DefaultIdleTimeout / time.Second),
+		},
+		Monitoring: Monitoring{
+			StartMode: string(watcher.StartFromBeginning),
+		},
+		Updates: Updates{
+			MaxPerSecond: 4,
+		},
+	}
+}
+
+// Clone возвращает независимую копию настроек
+func (s *Settings) Clone() *Settings {
+	copied := *s
+	copied.Logs.SearchPaths = append([]string(nil), s.Logs.SearchPaths...)
+	return &copied
+}
+
+// Validate проверяет, что все значения настроек допустимы
+func (s *Settings) Validate() error {
+	if s.Logs.PollIntervalMs < minPollIntervalMs || s.Logs.PollIntervalMs > maxPollIntervalMs {
+		return fmt.Errorf("logs.pollIntervalMs must be between %d and %d, got %d",
+			minPollIntervalMs, maxPollIntervalMs, s.Logs.PollIntervalMs)
+	}
+
+	if s.Segmentation.IdleTimeoutSeconds < 1 || s.Segmentation.IdleTimeoutSeconds > maxIdleTimeout {
+		return fmt.Errorf("segmentation.idleTimeoutSeconds must be between 1 and %d, got %d",
+			maxIdleTimeout, s.Segmentation.IdleTimeoutSeconds)
+	}
+	if _, err := metrics.NewSegmentationPolicy(metrics.SegmentationMode(s.Segmentation.Mode), 0); err != nil {
+		return fmt.Errorf("segmentation.mode: %w", err)
+	}
+
+	mode, err := watcher.ParseStartMode(s.Monitoring.StartMode)
+	if err != nil {
+		return fmt.Errorf("monitoring.startMode: %w", err)
+	}
+	if mode == watcher.StartFromTime {
+		if _, err := time.Parse(time.RFC3339, s.Monitoring.StartTime); err != nil {
+			return fmt.Errorf("monitoring.startTime: %w", err)
+		}
+	}
+
+	if s.Updates.MaxPerSecond < minUpdatesPerSecond || s.Updates.MaxPerSecond > maxUpdatesPerSecond {
+		return fmt.Errorf("updates.maxPerSecond must be between %d and %d, got %d",
+			minUpdatesPerSecond, maxUpdatesPerSecond, s.Updates.MaxPerSecond)
+	}
+
+	return nil
+}
+
+// DefaultPath возвращает путь к файлу настроек в пользовательском каталоге
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find user config dir: %w", err)
+	}
+	return filepath.Join(dir, appDirName, fileName), nil
+}
+
+// Load читает настройки из файла, приводит их к текущей версии схемы и
+// проверяет. Если файла нет, возвращаются настройки по умолчанию. При ошибке
+// возвращаются настройки по умолчанию вместе с ошибкой.
+func Load(path string) (*Settings, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Default(), nil
+	}
+	if err != nil {
+		return Default(), fmt.Errorf("failed to read settings: %w", err)
+	}
+
+	data, err = migrate(data)
+	if err != nil {
+		return Default(), err
+	}
+
+	// Поля, которых нет в файле, остаются со значениями по умолчанию
+	s := Default()
+	if err := json.Unmarshal(data, s); err != nil {
+		return Default(), fmt.Errorf("failed to parse settings: %w", err)
+	}
+
+	if err := s.Validate(); err != nil {
+		return Default(), fmt.Errorf("invalid settings: %w", err)
+	}
+
+	return s, nil
+}
+
+// Backup переименовывает файл настроек, который не удалось прочитать, в
+// <path>.bak, чтобы следующее сохранение не затерло его. Возвращает новый путь.
+func Backup(path string) (string, error) {
+	backup := path + ".bak"
+	if err := os.Rename(path, backup); err != nil {
+		return "", fmt.Errorf("failed to back up settings: %w", err)
+	}
+	return backup, nil
+}
+
+// Save записывает настройки в файл, создавая каталог при необходимости.
+// Сами настройки не меняются: версия проставляется в копии.
+func Save(path string, s *Settings) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create settings dir: %w", err)
+	}
+
+	saved := *s
+	saved.Version = CurrentVersion
+	data, err := json.MarshalIndent(&saved, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode settings: %w", err)
+	}
+
+	// Пишем во временный файл и переименовываем, чтобы не оставить половину файла
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write settings: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace settings: %w", err)
+	}
+
+	return nil
+}
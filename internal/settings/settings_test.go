@@ -0,0 +1,111 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeSettings записывает файл настроек во временный каталог
+func writeSettings(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), fileName)
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadMigratesVersion0(t *testing.T) {
+	// Файл, сохраненный до появления version и logs
+	path := writeSettings(t, `{
+		"segmentation": {"mode": "kills", "idleTimeoutSeconds": 20},
+		"monitoring": {"startMode": "end"},
+		"updates": {"maxPerSecond": 10}
+	}`)
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Version != CurrentVersion {
+		t.Errorf("Version = %d, want %d", s.Version, CurrentVersion)
+	}
+	if s.Segmentation.Mode != "kills" || s.Segmentation.IdleTimeoutSeconds != 20 {
+		t.Errorf("Segmentation = %+v, want kills/20", s.Segmentation)
+	}
+	if s.Monitoring.StartMode != "end" || s.Updates.MaxPerSecond != 10 {
+		t.Errorf("Monitoring = %+v, Updates = %+v", s.Monitoring, s.Updates)
+	}
+
+	// Новый раздел заполнен значениями по умолчанию
+	defaults := Default()
+	if s.Logs.PollIntervalMs != defaults.Logs.PollIntervalMs || len(s.Logs.SearchPaths) != len(defaults.Logs.SearchPaths) {
+		t.Errorf("Logs = %+v, want defaults", s.Logs)
+	}
+}
+
+func TestLoadRejectsNewerVersion(t *testing.T) {
+	path := writeSettings(t, `{"version": 99, "updates": {"maxPerSecond": 10}}`)
+
+	s, err := Load(path)
+	if err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Fatalf("err = %v, want newer version error", err)
+	}
+	if s.Updates.MaxPerSecond != Default().Updates.MaxPerSecond {
+		t.Errorf("got settings from the newer file: %+v", s.Updates)
+	}
+}
+
+func TestLoadReportsInvalidField(t *testing.T) {
+	path := writeSettings(t, `{"version": 1, "logs": {"pollIntervalMs": 1}}`)
+
+	s, err := Load(path)
+	if err == nil || !strings.Contains(err.Error(), "logs.pollIntervalMs") {
+		t.Fatalf("err = %v, want logs.pollIntervalMs error", err)
+	}
+	if s.Logs.PollIntervalMs != Default().Logs.PollIntervalMs {
+		t.Errorf("PollIntervalMs = %d, want default", s.Logs.PollIntervalMs)
+	}
+}
+
+func TestBackupKeepsUnreadableFile(t *testing.T) {
+	data := `{"version": 99}`
+	path := writeSettings(t, data)
+
+	backup, err := Backup(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if backup != path+".bak" {
+		t.Errorf("backup = %s, want %s.bak", backup, path)
+	}
+	if saved, err := os.ReadFile(backup); err != nil || string(saved) != data {
+		t.Errorf("backup content = %q, %v", saved, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("settings file still exists: %v", err)
+	}
+}
+
+func TestSaveDoesNotModifySettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), fileName)
+	s := Default()
+	s.Version = 0
+
+	if err := Save(path, s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Version != 0 {
+		t.Errorf("Save changed Version to %d", s.Version)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Version != CurrentVersion {
+		t.Errorf("saved Version = %d, want %d", loaded.Version, CurrentVersion)
+	}
+}
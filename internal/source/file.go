@@ -0,0 +1,90 @@
+package source
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"aocdpsmetr/internal/watcher"
+)
+
+func init() {
+	Register("file", newFile)
+	Register("dir", newDir)
+}
+
+// tailer читает дописываемый файл лога через watcher.Watcher
+type tailer struct {
+	*watcher.Watcher
+	stream
+	backlog bool // Прочитать резервные логи каталога перед запуском
+}
+
+// newFile создает источник, который следит за одним файлом лога
+func newFile(cfg Config) (EventSource, error) {
+	path, err := cfg.logPath()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("log file not found: %w", err)
+	}
+
+	t := &tailer{stream: newStream()}
+	t.Watcher = watcher.NewWatcher(path, t.sendEvents)
+	t.configure(cfg)
+	return t, nil
+}
+
+// newDir создает источник, который следит за каталогом логов и переключается
+// на самый новый файл. Пустой Path означает каталог файла лога по умолчанию.
+func newDir(cfg Config) (EventSource, error) {
+	dir := cfg.Path
+	if dir == "" {
+		path, err := cfg.logPath()
+		if err != nil {
+			return nil, err
+		}
+		dir = filepath.Dir(path)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("log directory not found: %s", dir)
+	}
+
+	t := &tailer{stream: newStream()}
+	t.Watcher = watcher.NewDirWatcher(dir, t.sendEvents)
+	if cfg.ImportBacklog {
+		t.backlog = true
+	}
+	t.configure(cfg)
+	return t, nil
+}
+
+// configure передает watcher общие настройки и обработчики
+func (t *tailer) configure(cfg Config) {
+	t.OnRotate(func(reason string) { t.send(Batch{Rotated: reason}) })
+	t.OnError(t.fail)
+	if cfg.Start.Mode != "" {
+		t.SetStartPosition(cfg.Start)
+	}
+	t.SetPollInterval(cfg.PollInterval)
+}
+
+// Start читает резервные логи, если нужно, и запускает watcher
+func (t *tailer) Start() error {
+	// Резервные логи старше текущего, поэтому обрабатываем их первыми
+	if t.backlog {
+		imported, err := t.ImportBacklog()
+		if err != nil {
+			return fmt.Errorf("failed to import backlog: %w", err)
+		}
+		fmt.Printf("Imported %d backup logs\n", imported)
+	}
+	return t.Watcher.Start()
+}
+
+// Stop останавливает watcher
+func (t *tailer) Stop() {
+	t.cancel()
+	t.Watcher.Stop()
+}
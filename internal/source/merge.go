@@ -0,0 +1,64 @@
+package source
+
+import "fmt"
+
+// merged объединяет несколько источников в один
+type merged struct {
+	stream
+	sources []EventSource
+}
+
+// Merge объединяет источники: события и ошибки всех источников приходят в
+// общие каналы. Один источник возвращается как есть.
+func Merge(sources ...EventSource) EventSource {
+	if len(sources) == 1 {
+		return sources[0]
+	}
+	return &merged{stream: newStream(), sources: sources}
+}
+
+// Sources возвращает объединенные источники
+func (m *merged) Sources() []EventSource {
+	return m.sources
+}
+
+// Start запускает все источники. Если один не запустился, остальные останавливаются.
+func (m *merged) Start() error {
+	// Источник может отдавать события уже внутри Start, поэтому читаем заранее
+	for _, src := range m.sources {
+		go m.forward(src)
+	}
+
+	for i, src := range m.sources {
+		if err := src.Start(); err != nil {
+			for _, started := range m.sources[:i] {
+				started.Stop()
+			}
+			m.cancel()
+			return fmt.Errorf("source %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Stop останавливает все источники
+func (m *merged) Stop() {
+	m.cancel()
+	for _, src := range m.sources {
+		src.Stop()
+	}
+}
+
+// forward передает события и ошибки источника в общие каналы до остановки
+func (m *merged) forward(src EventSource) {
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case batch := <-src.Events():
+			m.send(batch)
+		case err := <-src.Errors():
+			m.fail(err)
+		}
+	}
+}
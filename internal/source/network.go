@@ -0,0 +1,109 @@
+package source
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+)
+
+func init() {
+	Register("network", newNetwork)
+}
+
+// DefaultAddress адрес приема логов по сети по умолчанию. Порт открыт только
+// на этом компьютере; чтобы принимать логи с других машин, адрес задается
+// явно, например "0.0.0.0:7777".
+const DefaultAddress = "127.0.0.1:7777"
+
+// receiver принимает строки лога по TCP, например от `tail -f AOC.log | nc host 7777`.
+// Каждое соединение читается отдельно; события всех соединений идут в один канал.
+type receiver struct {
+	stream
+	address  string
+	listener net.Listener
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+// newNetwork создает источник, который принимает лог по сети
+func newNetwork(cfg Config) (EventSource, error) {
+	address := cfg.Address
+	if address == "" {
+		address = DefaultAddress
+	}
+	return &receiver{
+		stream:  newStream(),
+		address: address,
+		conns:   make(map[net.Conn]struct{}),
+	}, nil
+}
+
+// Start открывает порт и начинает принимать соединения
+func (r *receiver) Start() error {
+	listener, err := net.Listen("tcp", r.address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", r.address, err)
+	}
+	r.listener = listener
+	fmt.Println("Receiving logs on", listener.Addr())
+
+	go r.accept()
+	return nil
+}
+
+// Stop закрывает порт и все соединения
+func (r *receiver) Stop() {
+	r.cancel()
+	if r.listener != nil {
+		r.listener.Close()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for conn := range r.conns {
+		conn.Close()
+	}
+}
+
+// accept принимает соединения, пока порт открыт
+func (r *receiver) accept() {
+	for {
+		conn, err := r.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				r.fail(fmt.Errorf("failed to accept connection: %w", err))
+			}
+			return
+		}
+
+		// Stop отменяет контекст до того, как закрыть соединения под r.mu,
+		// поэтому соединение, принятое после остановки, закрываем сразу
+		r.mu.Lock()
+		if r.ctx.Err() != nil {
+			r.mu.Unlock()
+			conn.Close()
+			return
+		}
+		r.conns[conn] = struct{}{}
+		r.mu.Unlock()
+		go r.serve(conn)
+	}
+}
+
+// serve читает лог из одного соединения до его закрытия
+func (r *receiver) serve(conn net.Conn) {
+	fmt.Println("Log sender connected:", conn.RemoteAddr())
+	defer func() {
+		r.mu.Lock()
+		delete(r.conns, conn)
+		r.mu.Unlock()
+		conn.Close()
+		fmt.Println("Log sender disconnected:", conn.RemoteAddr())
+	}()
+
+	if err := readLines(conn, r.sendEvents); err != nil && r.ctx.Err() == nil {
+		r.fail(fmt.Errorf("connection %s: %w", conn.RemoteAddr(), err))
+	}
+}
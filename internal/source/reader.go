@@ -0,0 +1,57 @@
+package source
+
+import (
+	"io"
+	"os"
+
+	"aocdpsmetr/internal/parser"
+)
+
+func init() {
+	Register("stdin", newStdin)
+}
+
+// reader читает строки лога из потока, например `tail -f AOC.log | aocdpsmetr`
+type reader struct {
+	stream
+	input io.Reader
+}
+
+// newStdin создает источник, который читает лог из стандартного ввода
+func newStdin(cfg Config) (EventSource, error) {
+	input := cfg.Input
+	if input == nil {
+		input = os.Stdin
+	}
+	return &reader{stream: newStream(), input: input}, nil
+}
+
+// Start начинает чтение потока
+func (r *reader) Start() error {
+	go func() {
+		if err := readLines(r.input, r.sendEvents); err != nil {
+			r.fail(err)
+			return
+		}
+		r.send(Batch{Finished: true})
+	}()
+	return nil
+}
+
+// Stop прекращает передачу событий. Заблокированное чтение стандартного
+// ввода прервать нельзя, оно завершится вместе с потоком.
+func (r *reader) Stop() {
+	r.cancel()
+}
+
+// readLines разбирает строки лога из потока до его конца. События
+// передаются, когда прочитанные данные закончились, чтобы живой поток
+// не ждал заполнения пачки.
+func readLines(input io.Reader, send func([]parser.Event)) error {
+	lines := parser.LineReader{Live: true}
+	_, err := lines.Read(input, func(batch []parser.Event, read int64) error {
+		send(batch)
+		return nil
+	})
+	return err
+}
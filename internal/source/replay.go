@@ -0,0 +1,43 @@
+package source
+
+import (
+	"time"
+
+	"aocdpsmetr/internal/replay"
+)
+
+func init() {
+	Register("replay", newReplay)
+}
+
+// player воспроизводит записанный лог через replay.Player
+type player struct {
+	*replay.Player
+	stream
+}
+
+// newReplay создает источник, который воспроизводит файл лога с паузами между событиями
+func newReplay(cfg Config) (EventSource, error) {
+	path, err := cfg.logPath()
+	if err != nil {
+		return nil, err
+	}
+	speed, err := replay.ParseSpeed(cfg.Speed)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &player{stream: newStream()}
+	p.Player = replay.NewPlayer(path, p.sendEvents)
+	p.SetSpeed(speed)
+	p.OnSeek(func(at time.Time) { p.send(Batch{Reset: true}) })
+	p.OnFinish(func() { p.send(Batch{Finished: true}) })
+	return p, nil
+}
+
+// Stop останавливает воспроизведение
+func (p *player) Stop() {
+	// Сначала отменяем отправку, иначе воспроизведение может ждать читателя
+	p.cancel()
+	p.Player.Stop()
+}
@@ -0,0 +1,200 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"aocdpsmetr/internal/parser"
+	"aocdpsmetr/internal/replay"
+	"aocdpsmetr/internal/watcher"
+)
+
+// EventSource источник событий боевого лога: файл, каталог, поток, сеть.
+// Start запускает чтение, Stop его останавливает. События и ошибки приходят
+// в каналы, которые нужно читать до вызова Stop. Каналы не закрываются.
+type EventSource interface {
+	Start() error
+	Stop()
+	Events() <-chan Batch
+	Errors() <-chan error
+}
+
+// Batch пачка событий и сигналы источника в том порядке, в каком они произошли
+type Batch struct {
+	Events   []parser.Event
+	Reset    bool   // Поток начался заново (перемотка воспроизведения), статистику нужно сбросить
+	Rotated  string // Файл лога обрезан ("truncated") или заменен ("replaced")
+	Finished bool   // Данные закончились, новых событий не будет
+}
+
+// Clocked источник со своим временем, например воспроизведение лога
+type Clocked interface {
+	Clock() time.Time
+}
+
+// Poller источник, который проверяет файл с заданным интервалом
+type Poller interface {
+	SetPollInterval(interval time.Duration)
+}
+
+// Playback источник с управлением воспроизведением
+type Playback interface {
+	Pause()
+	Resume()
+	SetSpeed(speed replay.Speed)
+	Seek(at time.Time)
+	State() replay.State
+}
+
+// Config параметры источника. Каждый источник читает только свои поля.
+type Config struct {
+	Kind          string `json:"kind"`          // Имя источника в реестре, см. Kinds
+	Path          string `json:"path"`          // Файл лога (file, replay) или каталог логов (dir)
+	Address       string `json:"address"`       // Адрес для приема логов, например "0.0.0.0:7777"; по умолчанию DefaultAddress (network)
+	Speed         string `json:"speed"`         // "1x", "4x", "16x" или "instant" (replay)
+	ImportBacklog bool   `json:"importBacklog"` // Сначала прочитать резервные логи каталога (dir)
+
+	// Заполняются приложением
+	DefaultLog   string                `json:"-"` // Файл лога, если Path пустой
+	Start        watcher.StartPosition `json:"-"` // С какого места читать существующий файл (file, dir)
+	PollInterval time.Duration         `json:"-"` // Интервал проверки файла (file, dir)
+	Input        io.Reader             `json:"-"` // Поток для stdin; nil - os.Stdin
+}
+
+// logPath возвращает файл лога из настроек источника
+func (c Config) logPath() (string, error) {
+	if c.Path != "" {
+		return c.Path, nil
+	}
+	if c.DefaultLog != "" {
+		return c.DefaultLog, nil
+	}
+	return "", fmt.Errorf("%s: log file not specified", c.Kind)
+}
+
+// Factory создает источник по настройкам
+type Factory func(cfg Config) (EventSource, error)
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]Factory{}
+)
+
+// Register добавляет источник в реестр. Источники этого пакета
+// регистрируются сами; новый источник достаточно зарегистрировать в init.
+func Register(kind string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[kind] = factory
+}
+
+// New создает источник по имени из cfg.Kind
+func New(cfg Config) (EventSource, error) {
+	registryMu.Lock()
+	factory, exists := registry[cfg.Kind]
+	registryMu.Unlock()
+
+	if !exists {
+		return nil, fmt.Errorf("unknown event source %q", cfg.Kind)
+	}
+	return factory(cfg)
+}
+
+// Kinds возвращает имена зарегистрированных источников
+func Kinds() []string {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	kinds := make([]string, 0, len(registry))
+	for kind := range registry {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// group источник, объединяющий несколько других
+type group interface {
+	Sources() []EventSource
+}
+
+// IsMerged сообщает, что src объединяет несколько источников через Merge
+func IsMerged(src EventSource) bool {
+	_, ok := src.(group)
+	return ok
+}
+
+// Walk вызывает fn для источника и для всех источников, объединенных Merge
+func Walk(src EventSource, fn func(EventSource)) {
+	fn(src)
+	if parent, ok := src.(group); ok {
+		for _, child := range parent.Sources() {
+			Walk(child, fn)
+		}
+	}
+}
+
+// Find возвращает первый источник, реализующий T, например Playback
+func Find[T any](src EventSource) (T, bool) {
+	var found T
+	ok := false
+	Walk(src, func(child EventSource) {
+		if match, matches := child.(T); matches && !ok {
+			found, ok = match, true
+		}
+	})
+	return found, ok
+}
+
+// stream каналы источника. Отправка ждет читателя, пока источник не остановлен.
+type stream struct {
+	events chan Batch
+	errors chan error
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newStream() stream {
+	ctx, cancel := context.WithCancel(context.Background())
+	return stream{
+		events: make(chan Batch),
+		errors: make(chan error),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Events возвращает канал событий источника
+func (s *stream) Events() <-chan Batch {
+	return s.events
+}
+
+// Errors возвращает канал ошибок источника
+func (s *stream) Errors() <-chan error {
+	return s.errors
+}
+
+// send передает пачку читателю или отбрасывает ее после остановки
+func (s *stream) send(batch Batch) {
+	select {
+	case s.events <- batch:
+	case <-s.ctx.Done():
+	}
+}
+
+// sendEvents передает события; подходит как callback для watcher и replay
+func (s *stream) sendEvents(events []parser.Event) {
+	s.send(Batch{Events: events})
+}
+
+// fail передает ошибку читателю или отбрасывает ее после остановки
+func (s *stream) fail(err error) {
+	select {
+	case s.errors <- err:
+	case <-s.ctx.Done():
+	}
+}
@@ -0,0 +1,150 @@
+package watcher
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"aocdpsmetr/internal/parser"
+)
+
+// LogPattern шаблон имен файлов лога игры: текущий AOC.log и резервные AOC-backup-*.log
+const LogPattern = "AOC*.log"
+
+// BackupPattern шаблон имен резервных файлов лога
+const BackupPattern = "AOC-backup-*.log"
+
+// NewDirWatcher создает watcher, который следит за каталогом логов и
+// переключается на самый новый файл лога, когда игра его создает
+func NewDirWatcher(dir string, callback func([]parser.Event)) *Watcher {
+	w := NewWatcher(filepath.Join(dir, "AOC.log"), callback)
+	w.dir = filepath.Clean(dir)
+	return w
+}
+
+// FindLogs возвращает файлы каталога, подходящие под шаблон, от старых к новым
+func FindLogs(dir, pattern string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, pattern))
+	if err != nil {
+		return nil, err
+	}
+
+	type logFile struct {
+		path string
+		info os.FileInfo
+	}
+	files := make([]logFile, 0, len(matches))
+	for _, path := range matches {
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		files = append(files, logFile{path: path, info: info})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].info.ModTime().Before(files[j].info.ModTime())
+	})
+
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		paths = append(paths, file.path)
+	}
+	return paths, nil
+}
+
+// NewestLog возвращает самый новый активный (не резервный) файл лога в
+// каталоге или пустую строку
+func NewestLog(dir string) (string, error) {
+	logs, err := FindLogs(dir, LogPattern)
+	if err != nil {
+		return "", err
+	}
+
+	for i := len(logs) - 1; i >= 0; i-- {
+		if !isBackup(logs[i]) {
+			return logs[i], nil
+		}
+	}
+	return "", nil
+}
+
+// isBackup сообщает, что файл - резервная копия лога, в которую игра уже не пишет
+func isBackup(path string) bool {
+	matched, _ := filepath.Match(BackupPattern, filepath.Base(path))
+	return matched
+}
+
+// ImportBacklog обрабатывает резервные логи каталога от старых к новым и
+// передает их события в callback пачками. Для watcher файла (не каталога)
+// ничего не делает. Возвращает число обработанных файлов.
+func (w *Watcher) ImportBacklog() (int, error) {
+	if w.dir == "" {
+		return 0, nil
+	}
+
+	backups, err := FindLogs(w.dir, BackupPattern)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, path := range backups {
+		count, err := w.importFile(path)
+		if err != nil {
+			return i, fmt.Errorf("failed to import %s: %w", path, err)
+		}
+		w.logf("Imported %d events from %s\n", count, path)
+	}
+
+	return len(backups), nil
+}
+
+// importFile читает резервный лог целиком и возвращает число событий
+func (w *Watcher) importFile(path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	count := 0
+	lines := parser.LineReader{Parser: w.parser}
+	_, err = lines.Read(file, func(batch []parser.Event, _ int64) error {
+		count += len(batch)
+		if w.callback != nil {
+			w.callback(batch)
+		}
+		return nil
+	})
+	return count, err
+}
+
+// switchIfNewer переключает watcher на другой файл лога, если тот новее текущего
+func (w *Watcher) switchIfNewer(name string) {
+	if matched, _ := filepath.Match(LogPattern, filepath.Base(name)); !matched || isBackup(name) {
+		return
+	}
+
+	candidate, err := os.Stat(name)
+	if err != nil {
+		return
+	}
+
+	// Сравниваем с текущим файлом, а если он уже удален - с последним прочитанным
+	current := w.info
+	if info, err := os.Stat(w.filename); err == nil {
+		current = info
+	}
+	if current != nil && !candidate.ModTime().After(current.ModTime()) {
+		return
+	}
+
+	// Дочитываем текущий файл, чтобы не потерять его последние строки
+	w.processFileUpdate()
+
+	w.filename = name
+	w.info = nil
+	w.rotate("switched to " + filepath.Base(name))
+	w.processFileUpdate()
+}
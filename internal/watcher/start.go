@@ -0,0 +1,120 @@
+package watcher
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"aocdpsmetr/internal/parser"
+)
+
+// StartMode определяет, с какого места читать уже записанную часть лога
+type StartMode string
+
+const (
+	// StartFromEnd - только новые строки, существующий файл пропускается
+	StartFromEnd StartMode = "end"
+	// StartFromBeginning - весь существующий файл
+	StartFromBeginning StartMode = "beginning"
+	// StartFromTime - события начиная с заданного времени
+	StartFromTime StartMode = "timestamp"
+	// StartFromLastSession - события последнего запуска игры в этом логе
+	StartFromLastSession StartMode = "session"
+)
+
+// StartPosition задает начальную позицию чтения
+type StartPosition struct {
+	Mode  StartMode
+	Since time.Time // Для StartFromTime
+}
+
+// ParseStartMode проверяет название режима
+func ParseStartMode(value string) (StartMode, error) {
+	switch mode := StartMode(value); mode {
+	case StartFromEnd, StartFromBeginning, StartFromTime, StartFromLastSession:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown start mode: %q", value)
+	}
+}
+
+// SetStartPosition задает, с какого места читать существующий файл при Start
+func (w *Watcher) SetStartPosition(position StartPosition) {
+	w.start = position
+}
+
+// startOffset возвращает смещение, с которого нужно читать существующий файл
+func (w *Watcher) startOffset(file *os.File, size int64) (int64, error) {
+	switch w.start.Mode {
+	case StartFromEnd:
+		return lastLineEnd(file, size)
+	case StartFromLastSession:
+		return lastSessionStart(file)
+	default:
+		return 0, nil
+	}
+}
+
+// filterSince отбрасывает события раньше заданного времени
+func filterSince(events []parser.Event, since time.Time) []parser.Event {
+	filtered := events[:0]
+	for _, event := range events {
+		if !event.Time().Before(since) {
+			filtered = append(filtered, event)
+		}
+	}
+	return filtered
+}
+
+// lastLineEnd возвращает смещение сразу после последнего перевода строки,
+// чтобы недописанная строка в конце файла была прочитана позже целиком
+func lastLineEnd(file *os.File, size int64) (int64, error) {
+	buf := make([]byte, 4096)
+	for end := size; end > 0; {
+		start := end - int64(len(buf))
+		if start < 0 {
+			start = 0
+		}
+
+		n, err := file.ReadAt(buf[:end-start], start)
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+		if i := bytes.LastIndexByte(buf[:n], '\n'); i >= 0 {
+			return start + int64(i) + 1, nil
+		}
+		end = start
+	}
+	return 0, nil
+}
+
+// lastSessionStart находит начало последнего запуска игры. Счетчик кадров
+// "frame" растет в течение запуска и сбрасывается при перезапуске игры,
+// поэтому новая сессия начинается со строки, где он уменьшился.
+func lastSessionStart(file *os.File) (int64, error) {
+	reader := bufio.NewReader(file)
+	var offset, sessionStart int64
+	lastFrame := -1
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err == io.EOF {
+			return sessionStart, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		if record, decodeErr := parser.DecodeRecord(strings.TrimRight(line, "\r\n")); decodeErr == nil {
+			if record.Frame < lastFrame {
+				sessionStart = offset
+			}
+			lastFrame = record.Frame
+		}
+		offset += int64(len(line))
+	}
+}
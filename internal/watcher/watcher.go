@@ -1,10 +1,12 @@
 package watcher
 
 import (
-	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"sync/atomic"
 	"time"
 
 	"aocdpsmetr/internal/parser"
@@ -12,31 +14,74 @@ import (
 	"github.com/fsnotify/fsnotify"
 )
 
-// Watcher отслеживает изменения в файле лога
+// Watcher отслеживает изменения в файле лога. Позиция чтения хранится как
+// смещение в байтах, поэтому каждое обновление читает только дописанное.
 type Watcher struct {
 	filename string
 	parser   *parser.Parser
-	callback func([]interface{})
+	callback func([]parser.Event)
+	onRotate func(reason string)
+	onError  func(err error)
 	watcher  *fsnotify.Watcher
 	ctx      context.Context
 	cancel   context.CancelFunc
-	lastLine int
+	offset   int64
+	info     os.FileInfo // Файл, из которого читали в последний раз
+	dir      string      // Каталог логов в режиме слежения за самым новым файлом
+	start    StartPosition
+	poll     atomic.Int64 // Интервал проверки файла, в наносекундах
+	log      io.Writer    // Куда писать отладочный вывод
 }
 
+// DefaultPollInterval как часто по умолчанию проверять файл на случай пропущенных событий
+const DefaultPollInterval = 100 * time.Millisecond
+
 // NewWatcher создает новый watcher
-func NewWatcher(filename string, callback func([]interface{})) *Watcher {
+func NewWatcher(filename string, callback func([]parser.Event)) *Watcher {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &Watcher{
-		filename: filename,
+	w := &Watcher{
+		filename: filepath.Clean(filename),
 		parser:   parser.NewParser(),
 		callback: callback,
 		ctx:      ctx,
 		cancel:   cancel,
-		lastLine: 0,
+		start:    StartPosition{Mode: StartFromBeginning},
+		log:      os.Stdout,
+	}
+	w.poll.Store(int64(DefaultPollInterval))
+	return w
+}
+
+// SetPollInterval задает интервал проверки файла. Можно менять во время работы.
+func (w *Watcher) SetPollInterval(interval time.Duration) {
+	if interval > 0 {
+		w.poll.Store(int64(interval))
 	}
 }
 
+// SetLogOutput задает, куда писать отладочный вывод (по умолчанию os.Stdout).
+// Вызывается до Start.
+func (w *Watcher) SetLogOutput(out io.Writer) {
+	w.log = out
+}
+
+// logf пишет строку отладочного вывода
+func (w *Watcher) logf(format string, args ...any) {
+	fmt.Fprintf(w.log, format, args...)
+}
+
+// OnRotate задает обработчик, который вызывается, когда файл лога был
+// обрезан или заменен и чтение началось с начала нового файла
+func (w *Watcher) OnRotate(fn func(reason string)) {
+	w.onRotate = fn
+}
+
+// OnError задает обработчик ошибок чтения лога, возникших после запуска
+func (w *Watcher) OnError(fn func(err error)) {
+	w.onError = fn
+}
+
 // Start начинает мониторинг файла
 func (w *Watcher) Start() error {
 	// Создаем watcher
@@ -46,19 +91,23 @@ func (w *Watcher) Start() error {
 	}
 	w.watcher = watcher
 
-	// Добавляем файл для мониторинга
-	if err := w.watcher.Add(w.filename); err != nil {
-		return fmt.Errorf("failed to add file to watcher: %w", err)
+	// В режиме каталога начинаем с самого нового файла лога
+	if w.dir != "" {
+		if newest, err := NewestLog(w.dir); err == nil && newest != "" {
+			w.filename = filepath.Clean(newest)
+		}
+		w.logf("Following log directory: %s current file: %s\n", w.dir, w.filename)
 	}
 
-	// Читаем существующий файл для получения начальной позиции
-	if err := w.readExistingFile(); err != nil {
-		return fmt.Errorf("failed to read existing file: %w", err)
+	// Следим за каталогом, а не за файлом: при замене файла наблюдение
+	// за старым файлом теряется, а события каталога продолжают приходить
+	if err := w.watcher.Add(filepath.Dir(w.filename)); err != nil {
+		return fmt.Errorf("failed to add log dir to watcher: %w", err)
 	}
 
-	// Обрабатываем весь существующий файл при старте
+	// Обрабатываем существующий файл с начальной позиции; затем позиция встает в его конец
 	if err := w.processExistingFile(); err != nil {
-		fmt.Printf("Warning: failed to process existing file: %v\n", err)
+		w.logf("Warning: failed to process existing file: %v\n", err)
 	}
 
 	// Запускаем цикл мониторинга
@@ -77,55 +126,42 @@ func (w *Watcher) Stop() {
 	}
 }
 
-// readExistingFile читает существующий файл для получения начальной позиции
-func (w *Watcher) readExistingFile() error {
+// processExistingFile обрабатывает существующий файл начиная с заданной позиции
+func (w *Watcher) processExistingFile() error {
 	file, err := os.Open(w.filename)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	lineCount := 0
-
-	for scanner.Scan() {
-		lineCount++
+	if w.info, err = file.Stat(); err != nil {
+		return err
 	}
 
-	w.lastLine = lineCount
-	fmt.Println("Last line:", w.lastLine)
-	return scanner.Err()
-}
-
-// processExistingFile обрабатывает весь существующий файл
-func (w *Watcher) processExistingFile() error {
-	file, err := os.Open(w.filename)
+	start, err := w.startOffset(file, w.info.Size())
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	var events []interface{}
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		if event, err := w.parser.ParseLine(line); err == nil && event != nil {
-			events = append(events, event)
-		}
+	if _, err := file.Seek(start, io.SeekStart); err != nil {
+		return err
 	}
 
-	if len(events) > 0 && w.callback != nil {
-		fmt.Printf("Processing %d existing events\n", len(events))
-		w.callback(events)
+	var since time.Time
+	if w.start.Mode == StartFromTime {
+		since = w.start.Since
 	}
 
-	return scanner.Err()
+	count, read, err := w.readEvents(file, since)
+	w.offset = start + read
+	w.logf("Start offset: %d, processed %d existing events\n", w.offset, count)
+
+	return err
 }
 
 // watchLoop основной цикл мониторинга
 func (w *Watcher) watchLoop() {
-	ticker := time.NewTicker(100 * time.Millisecond) // Проверяем каждые 100мс
+	interval := time.Duration(w.poll.Load())
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
@@ -133,21 +169,38 @@ func (w *Watcher) watchLoop() {
 		case <-w.ctx.Done():
 			return
 		case event := <-w.watcher.Events:
-			if event.Op&fsnotify.Write == fsnotify.Write {
+			name := filepath.Clean(event.Name)
+			if name != w.filename {
+				// В режиме каталога переключаемся на новый файл лога
+				if w.dir != "" && event.Op&(fsnotify.Create|fsnotify.Write) != 0 {
+					w.switchIfNewer(name)
+				}
+				continue
+			}
+			switch {
+			case event.Op&(fsnotify.Write|fsnotify.Create) != 0:
 				w.processFileUpdate()
+			case event.Op&(fsnotify.Remove|fsnotify.Rename) != 0:
+				// Ждем, пока игра создаст новый файл; смену заметит processFileUpdate
+				w.logf("Log file %s: %s\n", event.Op, event.Name)
 			}
 		case err := <-w.watcher.Errors:
 			if err != nil {
-				fmt.Printf("Watcher error: %v\n", err)
+				w.fail(fmt.Errorf("watcher error: %w", err))
 			}
 		case <-ticker.C:
 			// Периодически проверяем файл на случай пропущенных событий
 			w.processFileUpdate()
+
+			if next := time.Duration(w.poll.Load()); next != interval {
+				interval = next
+				ticker.Reset(interval)
+			}
 		}
 	}
 }
 
-// processFileUpdate обрабатывает обновление файла
+// processFileUpdate читает строки, дописанные после сохраненного смещения
 func (w *Watcher) processFileUpdate() {
 	file, err := os.Open(w.filename)
 	if err != nil {
@@ -155,31 +208,76 @@ func (w *Watcher) processFileUpdate() {
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	currentLine := 0
-	var newEvents []interface{}
+	info, err := file.Stat()
+	if err != nil {
+		return
+	}
 
-	// Пропускаем уже прочитанные строки
-	for scanner.Scan() {
-		currentLine++
-		if currentLine <= w.lastLine {
-			continue
-		}
+	// Файл заменен другим или обрезан - читаем новый файл с начала
+	if w.info != nil && !os.SameFile(w.info, info) {
+		w.rotate("replaced")
+	} else if info.Size() < w.offset {
+		w.rotate("truncated")
+	}
+	w.info = info
 
-		line := scanner.Text()
-		if event, err := w.parser.ParseLine(line); err == nil && event != nil {
-			newEvents = append(newEvents, event)
-			fmt.Printf("Parsed event: %T\n", event)
-		} else if err != nil {
-			fmt.Printf("Parse error: %v\n", err)
-		}
+	// Размер файла не изменился - читать нечего
+	if info.Size() == w.offset {
+		return
+	}
+
+	if _, err := file.Seek(w.offset, io.SeekStart); err != nil {
+		w.fail(fmt.Errorf("seek error: %w", err))
+		return
+	}
+
+	count, read, err := w.readEvents(file, time.Time{})
+	if err != nil {
+		w.fail(fmt.Errorf("read error: %w", err))
 	}
 
 	// Обновляем позицию
-	w.lastLine = currentLine
+	w.offset += read
+	if count > 0 {
+		w.logf("Parsed %d new events\n", count)
+	}
+}
+
+// rotate сбрасывает позицию чтения и сообщает о смене файла
+func (w *Watcher) rotate(reason string) {
+	w.logf("Log file %s, reading from the beginning\n", reason)
+	w.offset = 0
+	if w.onRotate != nil {
+		w.onRotate(reason)
+	}
+}
 
-	// Вызываем callback с новыми событиями
-	if len(newEvents) > 0 && w.callback != nil {
-		w.callback(newEvents)
+// fail сообщает об ошибке чтения
+func (w *Watcher) fail(err error) {
+	w.logf("Watcher: %v\n", err)
+	if w.onError != nil {
+		w.onError(err)
 	}
 }
+
+// readEvents читает полные строки до конца файла и передает события в
+// callback пачками по мере чтения, отбрасывая события раньше since (нулевое
+// время - без отбора). Возвращает число переданных событий и прочитанных
+// байт. Игра может сбросить на диск половину строки; такой хвост без
+// перевода строки не читается и не засчитывается, поэтому следующее
+// обновление прочитает строку целиком.
+func (w *Watcher) readEvents(r io.Reader, since time.Time) (int, int64, error) {
+	count := 0
+	lines := parser.LineReader{Parser: w.parser, KeepPartial: true}
+	read, err := lines.Read(r, func(batch []parser.Event, read int64) error {
+		if !since.IsZero() {
+			batch = filterSince(batch, since)
+		}
+		if len(batch) > 0 && w.callback != nil {
+			count += len(batch)
+			w.callback(batch)
+		}
+		return nil
+	})
+	return count, read, err
+}
@@ -0,0 +1,291 @@
+package watcher
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"aocdpsmetr/internal/parser"
+)
+
+// combatLine возвращает строку лога с уроном i+1 по цели Mob<i>
+func combatLine(i int) string {
+	timestamp := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC).Add(time.Duration(i) * time.Second)
+	return fmt.Sprintf(`{"frame":%d,"category":"LogAoC_CombatLog","verbosity":"Display","message":"[x][  0]LogAoC_CombatLog: Display: CombatLog: Player hit: %d damage dealt to Mob%d - Slash","timestamp":"%s"}`+"\n",
+		i+1, i+1, i, timestamp.Format("2006-01-02T15:04:05.000Z"))
+}
+
+// amounts возвращает урон событий: по нему combatLine задает номер строки
+func amounts(t *testing.T, events []parser.Event) []int {
+	t.Helper()
+	result := make([]int, 0, len(events))
+	for _, event := range events {
+		damage, ok := event.(*parser.DamageEvent)
+		if !ok {
+			t.Fatalf("unexpected event %T", event)
+		}
+		result = append(result, damage.Amount)
+	}
+	return result
+}
+
+// collect возвращает watcher, который копит все полученные события
+func collect(path string, events *[]parser.Event) *Watcher {
+	w := NewWatcher(path, func(batch []parser.Event) { *events = append(*events, batch...) })
+	w.SetLogOutput(io.Discard)
+	return w
+}
+
+func TestProcessFileUpdateChunkedWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "AOCClient.log")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var content strings.Builder
+	for i := 0; i < 300; i++ {
+		content.WriteString(combatLine(i))
+	}
+	data := content.String()
+
+	var events []parser.Event
+	w := collect(path, &events)
+	if err := w.processExistingFile(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Игра сбрасывает строки на диск кусками произвольной длины, часто
+	// посреди строки; после каждого куска файл проверяется заново
+	rng := rand.New(rand.NewSource(1))
+	for len(data) > 0 {
+		n := 1 + rng.Intn(200)
+		if n > len(data) {
+			n = len(data)
+		}
+		if _, err := file.WriteString(data[:n]); err != nil {
+			t.Fatal(err)
+		}
+		data = data[n:]
+		w.processFileUpdate()
+	}
+
+	got := amounts(t, events)
+	if len(got) != 300 {
+		t.Fatalf("got %d events, want 300", len(got))
+	}
+	for i, amount := range got {
+		if amount != i+1 {
+			t.Fatalf("event %d has amount %d, want %d", i, amount, i+1)
+		}
+	}
+}
+
+func TestStartFromEndWithPartialLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "AOCClient.log")
+	line := combatLine(3)
+	initial := combatLine(0) + combatLine(1) + combatLine(2) + line[:len(line)/2]
+	if err := os.WriteFile(path, []byte(initial), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var events []parser.Event
+	w := collect(path, &events)
+	w.SetStartPosition(StartPosition{Mode: StartFromEnd})
+	if err := w.processExistingFile(); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("got %d events before the line was finished, want 0", len(events))
+	}
+
+	// Недописанная строка начата до запуска, но должна прочитаться целиком
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(line[len(line)/2:] + combatLine(4)); err != nil {
+		t.Fatal(err)
+	}
+	w.processFileUpdate()
+
+	got := amounts(t, events)
+	if len(got) != 2 || got[0] != 4 || got[1] != 5 {
+		t.Fatalf("got amounts %v, want [4 5]", got)
+	}
+}
+
+func TestProcessExistingFileDeliversBatches(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "AOCClient.log")
+	total := 2*parser.BatchSize + parser.BatchSize/2
+	var content strings.Builder
+	for i := 0; i < total; i++ {
+		content.WriteString(combatLine(i))
+	}
+	if err := os.WriteFile(path, []byte(content.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Первые 100 событий раньше начальной позиции
+	since := time.Date(2025, 1, 1, 10, 0, 100, 0, time.UTC)
+	var batches []int
+	var events []parser.Event
+	w := NewWatcher(path, func(batch []parser.Event) {
+		batches = append(batches, len(batch))
+		events = append(events, batch...)
+	})
+	w.SetLogOutput(io.Discard)
+	w.SetStartPosition(StartPosition{Mode: StartFromTime, Since: since})
+	if err := w.processExistingFile(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(batches) != 3 {
+		t.Errorf("got %d batches %v, want 3", len(batches), batches)
+	}
+	for _, size := range batches {
+		if size > parser.BatchSize {
+			t.Errorf("batch of %d events exceeds BatchSize", size)
+		}
+	}
+	got := amounts(t, events)
+	if len(got) != total-100 || got[0] != 101 || got[len(got)-1] != total {
+		t.Errorf("got %d events from %v, want %d from 101", len(got), got[:1], total-100)
+	}
+}
+
+func TestProcessFileUpdateRestartsReplacedFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "AOCClient.log")
+	if err := os.WriteFile(path, []byte(combatLine(0)+combatLine(1)+combatLine(2)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var events []parser.Event
+	var reasons []string
+	w := collect(path, &events)
+	w.SetStartPosition(StartPosition{Mode: StartFromBeginning})
+	w.OnRotate(func(reason string) { reasons = append(reasons, reason) })
+	if err := w.processExistingFile(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Игра перезапустилась и начала тот же файл заново: он стал короче
+	if err := os.WriteFile(path, []byte(combatLine(10)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	w.processFileUpdate()
+
+	// Лог заменен новым файлом, который длиннее прочитанной части старого
+	replacement := filepath.Join(dir, "AOCClient.new")
+	data := combatLine(20) + combatLine(21) + combatLine(22) + combatLine(23)
+	if err := os.WriteFile(replacement, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(replacement, path); err != nil {
+		t.Fatal(err)
+	}
+	w.processFileUpdate()
+	w.processFileUpdate()
+
+	if want := []string{"truncated", "replaced"}; strings.Join(reasons, ",") != strings.Join(want, ",") {
+		t.Errorf("rotate reasons = %v, want %v", reasons, want)
+	}
+	got := amounts(t, events)
+	if want := []int{1, 2, 3, 11, 21, 22, 23, 24}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got amounts %v, want %v", got, want)
+	}
+	if w.offset != int64(len(data)) {
+		t.Errorf("offset = %d, want %d", w.offset, len(data))
+	}
+}
+
+func TestImportBacklog(t *testing.T) {
+	dir := t.TempDir()
+	backup := func(name, data string, modTime time.Time) {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Файлы читаются по времени изменения, а не по имени; последняя
+	// строка резервного лога может быть без перевода строки
+	now := time.Now()
+	backup("AOC-backup-2.log", combatLine(0)+combatLine(1), now.Add(-2*time.Hour))
+	backup("AOC-backup-1.log", combatLine(2)+strings.TrimSuffix(combatLine(3), "\n"), now.Add(-time.Hour))
+	backup("AOC.log", combatLine(9), now)
+
+	var events []parser.Event
+	var log strings.Builder
+	w := NewDirWatcher(dir, func(batch []parser.Event) { events = append(events, batch...) })
+	w.SetLogOutput(&log)
+
+	imported, err := w.ImportBacklog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported != 2 {
+		t.Errorf("imported %d files, want 2", imported)
+	}
+	if got := amounts(t, events); fmt.Sprint(got) != "[1 2 3 4]" {
+		t.Errorf("got amounts %v, want [1 2 3 4]", got)
+	}
+	if !strings.Contains(log.String(), "Imported 2 events from") {
+		t.Errorf("log = %q", log.String())
+	}
+}
+
+// BenchmarkProcessFileUpdate измеряет чтение одной дописанной строки в конце
+// большого файла. Время не должно зависеть от размера уже прочитанной части.
+func BenchmarkProcessFileUpdate(b *testing.B) {
+	for _, size := range []int64{1 << 20, 100 << 20, 500 << 20} {
+		b.Run(fmt.Sprintf("%dMB", size>>20), func(b *testing.B) {
+			path := filepath.Join(b.TempDir(), "AOCClient.log")
+			file, err := os.Create(path)
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer file.Close()
+
+			// Разреженный файл нужного размера; его содержимое уже прочитано
+			if err := file.Truncate(size); err != nil {
+				b.Fatal(err)
+			}
+			if _, err := file.Seek(size, io.SeekStart); err != nil {
+				b.Fatal(err)
+			}
+
+			events := 0
+			w := NewWatcher(path, func(batch []parser.Event) { events += len(batch) })
+			w.SetLogOutput(io.Discard)
+			w.offset = size
+			if w.info, err = file.Stat(); err != nil {
+				b.Fatal(err)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := file.WriteString(combatLine(i)); err != nil {
+					b.Fatal(err)
+				}
+				w.processFileUpdate()
+			}
+			b.StopTimer()
+
+			if events != b.N {
+				b.Fatalf("got %d events, want %d", events, b.N)
+			}
+		})
+	}
+}